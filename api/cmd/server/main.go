@@ -2,17 +2,22 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/cole-zoom/dUW-app/api/internal/cache"
 	"github.com/cole-zoom/dUW-app/api/internal/clients"
 	"github.com/cole-zoom/dUW-app/api/internal/handlers"
+	"github.com/cole-zoom/dUW-app/api/internal/history"
+	"github.com/cole-zoom/dUW-app/api/internal/jobs"
 	"github.com/cole-zoom/dUW-app/api/internal/middleware"
 	"github.com/cole-zoom/dUW-app/api/internal/services"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -46,18 +51,16 @@ func main() {
 		port = "8080"
 	}
 
-	// Validate Polygon API key
-	polygonAPIKey := os.Getenv("POLYGON_API_KEY")
-	if polygonAPIKey == "" {
-		fmt.Fprintln(os.Stderr, "POLYGON_API_KEY environment variable not set")
+	// Build the market data providers in priority order; keys and limits come from <PROVIDER>_* variables
+	providerClient, breakers, rateLimits, err := newMarketDataClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to create market data provider: %v\n", err)
 		os.Exit(1)
 	}
-	log.Printf("Polygon API key loaded: %s...", polygonAPIKey[:8])
 
 	// Create database connection pool
 	var ctx context.Context = context.Background()
 	var pool *pgxpool.Pool
-	var err error
 
 	pool, err = pgxpool.New(ctx, connStr)
 	if err != nil {
@@ -77,45 +80,140 @@ func main() {
 	}
 	log.Printf("Database says: %s", greeting)
 
-	// Initialize handlers with database connection pool
-	portfolioHandler := handlers.NewPortfolioHandler(pool)
-	stockHandler := handlers.NewStockHandler(pool)
-	securitiesHandler := handlers.NewSecuritiesHandler(pool)
+	// Optionally save every provider response as a fixture for offline development
+	var marketDataClient clients.APIClient = providerClient
+	if os.Getenv("FIXTURES_RECORD") == "true" {
+		log.Printf("Recording market data fixtures to %s", fixturesDir())
+		marketDataClient = clients.NewRecordingClient(providerClient, fixturesDir())
+	}
+
+	// Closed bars are stored permanently so only missing dates are fetched from the provider
+	var historyClient clients.APIClient = marketDataClient
+	if os.Getenv("PRICE_HISTORY") != "false" {
+		historyClient = clients.NewPriceHistoryClient(marketDataClient, history.NewPostgresStore(pool))
+	}
 
-	// Initialize polygon API integration
-	polygonClient := clients.NewPolygonClient(polygonAPIKey)
-	polygonStockService := services.NewStockService(polygonClient)
+	// Initialize the market data provider behind a read-through cache
+	cachingClient := clients.NewCachingClient(historyClient, newCacheStore(pool), clients.DefaultCachePolicy())
+	polygonStockService := services.NewStockService(cachingClient)
 	polygonStockHandler := handlers.NewStockAPIHandler(polygonStockService)
 
+	// The securities sync talks to the provider directly, and only the primary one; caching bulk listing pages would only evict useful entries
+	securitiesSync := jobs.NewSecuritiesSync(pool, marketDataClient, securitiesSyncMarkets())
+
+	// Split histories are small and also served to users, so the split job goes through the cache
+	splitAdjustments := jobs.NewSplitAdjustments(pool, cachingClient)
+
+	// Subcommands run once and exit instead of starting the server
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "sync-securities":
+			if _, err := securitiesSync.Run(ctx); err != nil {
+				log.Printf("Securities sync failed: %v", err)
+				pool.Close()
+				os.Exit(1)
+			}
+			return
+		case "adjust-splits":
+			if _, err := splitAdjustments.Run(ctx); err != nil {
+				log.Printf("Split adjustment failed: %v", err)
+				pool.Close()
+				os.Exit(1)
+			}
+			return
+		default:
+			fmt.Fprintf(os.Stderr, "Unknown command %q (available: sync-securities, adjust-splits)\n", os.Args[1])
+			pool.Close()
+			os.Exit(2)
+		}
+	}
+
+	// Background jobs stop when the server shuts down
+	jobsCtx, stopJobs := context.WithCancel(ctx)
+	defer stopJobs()
+
+	if raw := os.Getenv("SECURITIES_SYNC_INTERVAL"); raw != "" {
+		interval, err := time.ParseDuration(raw)
+		if err != nil || interval <= 0 {
+			log.Printf("Warning: Invalid SECURITIES_SYNC_INTERVAL %q, scheduled sync disabled", raw)
+		} else {
+			go securitiesSync.Schedule(jobsCtx, interval)
+		}
+	}
+
+	if raw := os.Getenv("SPLIT_ADJUSTMENT_INTERVAL"); raw != "" {
+		interval, err := time.ParseDuration(raw)
+		if err != nil || interval <= 0 {
+			log.Printf("Warning: Invalid SPLIT_ADJUSTMENT_INTERVAL %q, scheduled split adjustment disabled", raw)
+		} else {
+			go splitAdjustments.Schedule(jobsCtx, interval)
+		}
+	}
+
+	// Build the securities index once up front; requests share it until the next refresh
+	securitiesIndex := services.NewSecuritiesIndex(pool)
+	if _, err := securitiesIndex.Refresh(ctx); err != nil {
+		log.Printf("Warning: Initial securities index build failed, will retry on first request: %v", err)
+	}
+	go securitiesIndex.Schedule(jobsCtx, securitiesIndexRefreshInterval())
+
+	// Initialize handlers with database connection pool
+	portfolioHandler := handlers.NewPortfolioHandler(pool, polygonStockService)
+	stockHandler := handlers.NewStockHandler(pool)
+	securitiesHandler := handlers.NewSecuritiesHandler(securitiesIndex)
+	transactionHandler := handlers.NewTransactionHandler(pool)
+	performanceHandler := handlers.NewPerformanceHandler(pool, polygonStockService)
+	splitAdjustmentHandler := handlers.NewSplitAdjustmentHandler(pool, splitAdjustments)
+
 	// All routes will be registered in the main mux with selective auth
 
 	// Create main mux for all routes
 	mux := http.NewServeMux()
 
 	// Register health endpoint directly (will be handled by selective auth)
-	mux.HandleFunc("GET /api/health", healthHandler)
+	mux.HandleFunc("GET /api/health", healthHandler(cachingClient, providerClient, breakers, rateLimits))
 
 	// Register all other API routes
 	mux.HandleFunc("GET /api/portfolios", portfolioHandler.GetPortfolios)
 	mux.HandleFunc("POST /api/portfolios", portfolioHandler.CreatePortfolio)
 	mux.HandleFunc("PUT /api/portfolios/{id}", portfolioHandler.UpdatePortfolio)
 	mux.HandleFunc("DELETE /api/portfolios/{id}", portfolioHandler.DeletePortfolio)
+	mux.HandleFunc("GET /api/portfolios/{id}/performance", performanceHandler.GetPerformance)
+	mux.HandleFunc("GET /api/portfolios/{id}/valuation", portfolioHandler.GetValuation)
+	mux.HandleFunc("GET /api/portfolios/{id}/income", performanceHandler.GetIncome)
+	mux.HandleFunc("GET /api/portfolios/{id}/news", portfolioHandler.GetNews)
 
 	mux.HandleFunc("GET /api/portfolios/{portfolioID}/stocks", stockHandler.GetStocks)
 	mux.HandleFunc("POST /api/portfolios/{portfolioID}/stocks", stockHandler.CreateStock)
 	mux.HandleFunc("PUT /api/portfolios/{portfolioID}/stocks/{stockID}", stockHandler.UpdateStock)
 	mux.HandleFunc("DELETE /api/portfolios/{portfolioID}/stocks/{stockID}", stockHandler.DeleteStock)
 	mux.HandleFunc("PATCH /api/portfolios/{portfolioID}/stocks/{stockID}/move", stockHandler.MoveStock)
+
+	mux.HandleFunc("GET /api/portfolios/{portfolioID}/transactions", transactionHandler.GetTransactions)
+	mux.HandleFunc("POST /api/portfolios/{portfolioID}/transactions", transactionHandler.CreateTransaction)
+	mux.HandleFunc("DELETE /api/portfolios/{portfolioID}/transactions/{transactionID}", transactionHandler.DeleteTransaction)
+
+	mux.HandleFunc("GET /api/portfolios/{portfolioID}/split-adjustments", splitAdjustmentHandler.GetSplitAdjustments)
+	mux.HandleFunc("POST /api/portfolios/{portfolioID}/split-adjustments/{adjustmentID}/apply", splitAdjustmentHandler.ApplySplitAdjustment)
+	mux.HandleFunc("POST /api/portfolios/{portfolioID}/split-adjustments/{adjustmentID}/dismiss", splitAdjustmentHandler.DismissSplitAdjustment)
+
 	mux.HandleFunc("GET /api/stocks/suggestions", polygonStockHandler.GetSuggestedStocks)
 
-	// Stock data endpoints (Polygon API)
+	// Stock data endpoints (market data provider)
 	mux.HandleFunc("GET /api/stocks/{ticker}/aggregates", polygonStockHandler.GetAggregates)
 	mux.HandleFunc("GET /api/stocks/{ticker}/details", polygonStockHandler.GetTickerDetails)
 	mux.HandleFunc("GET /api/stocks/{ticker}/previous", polygonStockHandler.GetPreviousClose)
+	mux.HandleFunc("GET /api/stocks/{ticker}/dividends", polygonStockHandler.GetDividends)
+	mux.HandleFunc("GET /api/stocks/{ticker}/splits", polygonStockHandler.GetSplits)
+	mux.HandleFunc("GET /api/stocks/{ticker}/news", polygonStockHandler.GetNews)
+	mux.HandleFunc("GET /api/stocks/{ticker}/financials", polygonStockHandler.GetFinancials)
+	mux.HandleFunc("GET /api/market/status", polygonStockHandler.GetMarketStatus)
 
 	mux.HandleFunc("GET /api/securities/trie", securitiesHandler.GetSecuritiesTrie)
 	mux.HandleFunc("GET /api/securities/search", securitiesHandler.SearchSecurities)
 
+	mux.HandleFunc("POST /api/admin/securities/refresh", middleware.RequireAdmin(securitiesHandler.RefreshSecurities))
+
 	// Create selective auth middleware
 	selectiveAuthHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Skip auth for health endpoint
@@ -139,12 +237,14 @@ func main() {
 		mux.ServeHTTP(w, r)
 	})
 
-	// Create server with configurable port
+	// Create server with configurable port. Handlers get a deadline a little inside the
+	// write timeout, leaving time to write an error once they give up.
+	writeTimeout := 10 * time.Second
 	var server *http.Server = &http.Server{
 		Addr:         ":" + port,
-		Handler:      middleware.CORS(selectiveAuthHandler),
+		Handler:      middleware.CORS(middleware.Deadline(selectiveAuthHandler, writeTimeout-time.Second)),
 		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 10 * time.Second,
+		WriteTimeout: writeTimeout,
 		IdleTimeout:  60 * time.Second,
 	}
 
@@ -161,6 +261,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Shutting down server...")
+	stopJobs()
 
 	// Give outstanding requests a 30-second deadline to complete
 	var shutdownCtx context.Context
@@ -175,9 +276,193 @@ func main() {
 	log.Println("Server exited")
 }
 
-// healthHandler provides a simple health check endpoint
-func healthHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	fmt.Fprint(w, `{"status":"healthy","timestamp":"`, time.Now().Format(time.RFC3339), `"}`)
+// securitiesSyncMarkets reads the Polygon markets to sync from SECURITIES_SYNC_MARKETS
+// (comma separated, default "stocks")
+func securitiesSyncMarkets() []string {
+	raw := os.Getenv("SECURITIES_SYNC_MARKETS")
+	if raw == "" {
+		return []string{"stocks"}
+	}
+
+	var markets []string
+	for _, market := range strings.Split(raw, ",") {
+		if market = strings.TrimSpace(market); market != "" {
+			markets = append(markets, market)
+		}
+	}
+	return markets
+}
+
+// securitiesIndexRefreshInterval reads SECURITIES_INDEX_REFRESH_INTERVAL (default 1h)
+func securitiesIndexRefreshInterval() time.Duration {
+	interval := time.Hour
+	if raw := os.Getenv("SECURITIES_INDEX_REFRESH_INTERVAL"); raw != "" {
+		if parsed, err := time.ParseDuration(raw); err == nil && parsed > 0 {
+			interval = parsed
+		} else {
+			log.Printf("Warning: Invalid SECURITIES_INDEX_REFRESH_INTERVAL %q, using %v", raw, interval)
+		}
+	}
+	return interval
+}
+
+// newCacheStore picks the market data cache backend from CACHE_BACKEND
+// ("memory" by default, or "postgres") and CACHE_SIZE for the in-memory LRU.
+func newCacheStore(pool *pgxpool.Pool) cache.Store {
+	if os.Getenv("CACHE_BACKEND") == "postgres" {
+		log.Println("Using Postgres market data cache")
+		return cache.NewPostgresStore(pool)
+	}
+
+	size := 10000
+	if raw := os.Getenv("CACHE_SIZE"); raw != "" {
+		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 {
+			size = parsed
+		} else {
+			log.Printf("Warning: Invalid CACHE_SIZE %q, using %d", raw, size)
+		}
+	}
+	log.Printf("Using in-memory market data cache (%d entries)", size)
+	return cache.NewMemoryStore(size)
+}
+
+// newMarketDataClient builds the providers named in MARKET_DATA_PROVIDER (comma
+// separated, highest priority first, default "polygon"), each behind its own
+// circuit breaker, in a failover chain. PROVIDER_COOLDOWN sets how long a failing
+// provider is skipped (default 1m); BREAKER_FAILURE_THRESHOLD and BREAKER_OPEN_TIMEOUT
+// tune the breakers. Providers with a request queue are returned for the health check.
+func newMarketDataClient() (*clients.FailoverClient, []*clients.CircuitBreakerClient, map[string]clients.RateLimitReporter, error) {
+	names := []string{"polygon"}
+	if raw := os.Getenv("MARKET_DATA_PROVIDER"); raw != "" {
+		names = nil
+		for _, name := range strings.Split(raw, ",") {
+			if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+
+	breakerConfig := clients.DefaultBreakerConfig()
+	breakerConfig.FailureThreshold = envInt("BREAKER_FAILURE_THRESHOLD", breakerConfig.FailureThreshold)
+	breakerConfig.OpenTimeout = envDuration("BREAKER_OPEN_TIMEOUT", breakerConfig.OpenTimeout)
+
+	var providers []clients.NamedProvider
+	var breakers []*clients.CircuitBreakerClient
+	rateLimits := make(map[string]clients.RateLimitReporter)
+	for _, name := range names {
+		client, err := clients.NewProvider(name, providerConfig(name))
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if reporter, ok := client.(clients.RateLimitReporter); ok {
+			rateLimits[name] = reporter
+		}
+		breaker := clients.NewCircuitBreakerClient(name, client, breakerConfig)
+		breakers = append(breakers, breaker)
+		providers = append(providers, clients.NamedProvider{Name: name, Client: breaker})
+	}
+	if len(providers) == 0 {
+		return nil, nil, nil, fmt.Errorf("MARKET_DATA_PROVIDER lists no providers")
+	}
+
+	log.Printf("Using market data providers: %s", strings.Join(names, " -> "))
+	return clients.NewFailoverClient(providers, envDuration("PROVIDER_COOLDOWN", time.Minute)), breakers, rateLimits, nil
+}
+
+// providerConfig reads a provider's settings from variables prefixed with its name:
+// <PROVIDER>_API_KEYS (comma separated) or <PROVIDER>_API_KEY, <PROVIDER>_REQUESTS_PER_MINUTE,
+// <PROVIDER>_BURST, <PROVIDER>_BASE_URL, and <PROVIDER>_TIMEOUT. Unset values keep the
+// provider's defaults.
+func providerConfig(name string) clients.ProviderConfig {
+	prefix := strings.ToUpper(name) + "_"
+
+	var keys []string
+	for _, key := range strings.Split(os.Getenv(prefix+"API_KEYS"), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		if key := os.Getenv(prefix + "API_KEY"); key != "" {
+			keys = []string{key}
+		}
+	}
+
+	return clients.ProviderConfig{
+		APIKeys:           keys,
+		RequestsPerMinute: envInt(prefix+"REQUESTS_PER_MINUTE", 0),
+		Burst:             envInt(prefix+"BURST", 0),
+		BaseURL:           os.Getenv(prefix + "BASE_URL"),
+		Timeout:           envDuration(prefix+"TIMEOUT", 0),
+		FixturesDir:       fixturesDir(),
+	}
+}
+
+// envInt reads a positive integer from the environment, falling back on absence or error
+func envInt(name string, fallback int) int {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(raw)
+	if err != nil || parsed <= 0 {
+		log.Printf("Warning: Invalid %s %q, using %d", name, raw, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+// envDuration reads a positive duration from the environment, falling back on absence or error
+func envDuration(name string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return fallback
+	}
+	parsed, err := time.ParseDuration(raw)
+	if err != nil || parsed <= 0 {
+		log.Printf("Warning: Invalid %s %q, using %v", name, raw, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+// fixturesDir is where the fixtures provider reads and FIXTURES_RECORD writes (FIXTURES_DIR, default "fixtures")
+func fixturesDir() string {
+	if dir := os.Getenv("FIXTURES_DIR"); dir != "" {
+		return dir
+	}
+	return "fixtures"
+}
+
+// healthHandler provides a simple health check endpoint, including market data
+// cache counters, provider health, circuit breaker states, and rate limit queues.
+// Status is "degraded" while any breaker is not closed.
+func healthHandler(cachingClient *clients.CachingClient, providerClient *clients.FailoverClient, breakers []*clients.CircuitBreakerClient, rateLimits map[string]clients.RateLimitReporter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status := "healthy"
+		breakerStatuses := make([]clients.BreakerStatus, 0, len(breakers))
+		for _, breaker := range breakers {
+			breakerStatus := breaker.Status()
+			if breakerStatus.State != clients.BreakerClosed {
+				status = "degraded"
+			}
+			breakerStatuses = append(breakerStatuses, breakerStatus)
+		}
+
+		rateLimitStats := make(map[string][]clients.SchedulerStats, len(rateLimits))
+		for name, reporter := range rateLimits {
+			rateLimitStats[name] = reporter.RateLimitStats()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":      status,
+			"timestamp":   time.Now().Format(time.RFC3339),
+			"cache":       cachingClient.Stats(),
+			"providers":   providerClient.Health(),
+			"breakers":    breakerStatuses,
+			"rate_limits": rateLimitStats,
+		})
+	}
 }
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresStore persists cache entries in the market_data_cache table so they
+// survive restarts and are shared between server instances.
+type PostgresStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresStore creates a store backed by the given connection pool
+func NewPostgresStore(db *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// Get returns an unexpired value from the table
+func (s *PostgresStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	var value []byte
+	err := s.db.QueryRow(ctx, `
+		SELECT value FROM market_data_cache
+		WHERE key = $1 AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+	`, key).Scan(&value)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+	return value, true, nil
+}
+
+// Set upserts a value; a zero expiresAt is stored as NULL (never expires)
+func (s *PostgresStore) Set(ctx context.Context, key string, value []byte, expiresAt time.Time) error {
+	var expires *time.Time
+	if !expiresAt.IsZero() {
+		expires = &expiresAt
+	}
+
+	_, err := s.db.Exec(ctx, `
+		INSERT INTO market_data_cache (key, value, expires_at, updated_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		ON CONFLICT (key) DO UPDATE SET
+			value = EXCLUDED.value,
+			expires_at = EXCLUDED.expires_at,
+			updated_at = EXCLUDED.updated_at
+	`, key, value, expires)
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+// Package cache provides key/value backends for caching market data responses.
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Store is a byte-oriented cache backend. Values are opaque to the store;
+// callers handle encoding so any backend can hold any payload.
+type Store interface {
+	// Get returns the value for key and whether it was found and unexpired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key. A zero expiresAt means the entry never expires.
+	Set(ctx context.Context, key string, value []byte, expiresAt time.Time) error
+}
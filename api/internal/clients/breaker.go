@@ -0,0 +1,261 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/cole-zoom/dUW-app/api/internal/models"
+)
+
+// Circuit breaker states
+const (
+	BreakerClosed   = "closed"    // Calls flow normally
+	BreakerOpen     = "open"      // Calls fail fast without reaching the provider
+	BreakerHalfOpen = "half_open" // One probe call is let through to test recovery
+)
+
+// BreakerConfig controls when a breaker opens and how long it stays open
+type BreakerConfig struct {
+	FailureThreshold int           // Consecutive failures that open the breaker
+	OpenTimeout      time.Duration // How long to fail fast before probing again
+}
+
+// DefaultBreakerConfig returns the breaker settings used in production
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+	}
+}
+
+// CircuitOpenError is returned without calling the provider while its breaker is open.
+// It unwraps to ErrUpstreamUnavailable.
+type CircuitOpenError struct {
+	Provider   string
+	RetryAfter time.Duration // Until the breaker lets a probe through
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s circuit breaker is open", e.Provider)
+}
+
+func (e *CircuitOpenError) Unwrap() error {
+	return ErrUpstreamUnavailable
+}
+
+// BreakerStatus is a snapshot of one breaker for the health endpoint
+type BreakerStatus struct {
+	Provider            string     `json:"provider"`
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+}
+
+// CircuitBreakerClient wraps an APIClient so that once the provider looks down,
+// callers get an immediate CircuitOpenError instead of waiting out timeouts and the
+// rate limiter queue. After OpenTimeout a single probe is allowed; its outcome
+// closes the breaker or reopens it.
+type CircuitBreakerClient struct {
+	next   APIClient
+	name   string
+	config BreakerConfig
+
+	mu         sync.Mutex
+	state      string
+	failures   int
+	openedAt   time.Time
+	probing    bool
+	generation uint64 // Bumped each time the breaker opens
+}
+
+// admission is a call let through by the breaker: the half-open probe, or an
+// ordinary call made while closed during the given generation
+type admission struct {
+	probe      bool
+	generation uint64
+}
+
+func NewCircuitBreakerClient(name string, next APIClient, config BreakerConfig) *CircuitBreakerClient {
+	return &CircuitBreakerClient{
+		next:   next,
+		name:   name,
+		config: config,
+		state:  BreakerClosed,
+	}
+}
+
+// Status returns the breaker's current state
+func (b *CircuitBreakerClient) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := BreakerStatus{
+		Provider:            b.name,
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+	}
+	if b.state != BreakerClosed {
+		openedAt := b.openedAt
+		status.OpenedAt = &openedAt
+	}
+	return status
+}
+
+func (b *CircuitBreakerClient) GetSuggestedStocks(ctx context.Context, query string) ([]models.DisplayStock, error) {
+	return guard(ctx, b, func() ([]models.DisplayStock, error) { return b.next.GetSuggestedStocks(ctx, query) })
+}
+
+func (b *CircuitBreakerClient) ListTickersPage(ctx context.Context, params models.TickerListParams) (*models.TickerPage, error) {
+	return guard(ctx, b, func() (*models.TickerPage, error) { return b.next.ListTickersPage(ctx, params) })
+}
+
+func (b *CircuitBreakerClient) GetAggregates(ctx context.Context, ticker, multiplier, timespan, from, to string) (*models.AggregatesResponse, error) {
+	return guard(ctx, b, func() (*models.AggregatesResponse, error) {
+		return b.next.GetAggregates(ctx, ticker, multiplier, timespan, from, to)
+	})
+}
+
+func (b *CircuitBreakerClient) GetTickerDetails(ctx context.Context, ticker string) (*models.TickerDetails, error) {
+	return guard(ctx, b, func() (*models.TickerDetails, error) { return b.next.GetTickerDetails(ctx, ticker) })
+}
+
+func (b *CircuitBreakerClient) GetPreviousClose(ctx context.Context, ticker string) (*models.PreviousCloseResponse, error) {
+	return guard(ctx, b, func() (*models.PreviousCloseResponse, error) { return b.next.GetPreviousClose(ctx, ticker) })
+}
+
+func (b *CircuitBreakerClient) GetGroupedDaily(ctx context.Context, date string) (*models.GroupedDailyResponse, error) {
+	return guard(ctx, b, func() (*models.GroupedDailyResponse, error) { return b.next.GetGroupedDaily(ctx, date) })
+}
+
+func (b *CircuitBreakerClient) GetDividends(ctx context.Context, ticker string) (*models.DividendsResponse, error) {
+	return guard(ctx, b, func() (*models.DividendsResponse, error) { return b.next.GetDividends(ctx, ticker) })
+}
+
+func (b *CircuitBreakerClient) GetSplits(ctx context.Context, ticker string) (*models.SplitsResponse, error) {
+	return guard(ctx, b, func() (*models.SplitsResponse, error) { return b.next.GetSplits(ctx, ticker) })
+}
+
+func (b *CircuitBreakerClient) GetFinancials(ctx context.Context, ticker, timeframe string) (*models.FinancialsResponse, error) {
+	return guard(ctx, b, func() (*models.FinancialsResponse, error) { return b.next.GetFinancials(ctx, ticker, timeframe) })
+}
+
+func (b *CircuitBreakerClient) GetMarketStatus(ctx context.Context) (*models.MarketStatusResponse, error) {
+	return guard(ctx, b, func() (*models.MarketStatusResponse, error) { return b.next.GetMarketStatus(ctx) })
+}
+
+func (b *CircuitBreakerClient) GetMarketHolidays(ctx context.Context) (*models.MarketHolidaysResponse, error) {
+	return guard(ctx, b, func() (*models.MarketHolidaysResponse, error) { return b.next.GetMarketHolidays(ctx) })
+}
+
+func (b *CircuitBreakerClient) GetNews(ctx context.Context, params models.NewsParams) (*models.NewsPage, error) {
+	return guard(ctx, b, func() (*models.NewsPage, error) { return b.next.GetNews(ctx, params) })
+}
+
+// guard runs call if the breaker allows it and records the outcome
+func guard[T any](ctx context.Context, b *CircuitBreakerClient, call func() (T, error)) (T, error) {
+	admitted, err := b.allow()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	value, err := call()
+	if err != nil && ctx.Err() != nil {
+		// The caller gave up, which says nothing about the provider
+		b.release(admitted)
+		return value, err
+	}
+	b.record(admitted, err != nil && isOutage(err))
+	return value, err
+}
+
+// allow reports whether a call may proceed and how it was admitted
+func (b *CircuitBreakerClient) allow() (admission, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if wait := b.config.OpenTimeout - time.Since(b.openedAt); wait > 0 {
+			return admission{}, &CircuitOpenError{Provider: b.name, RetryAfter: wait}
+		}
+		log.Printf("CircuitBreaker - %s half-open, probing", b.name)
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return admission{probe: true, generation: b.generation}, nil
+	case BreakerHalfOpen:
+		if b.probing {
+			return admission{}, &CircuitOpenError{Provider: b.name, RetryAfter: time.Second}
+		}
+		b.probing = true
+		return admission{probe: true, generation: b.generation}, nil
+	default:
+		return admission{generation: b.generation}, nil
+	}
+}
+
+// release gives up a probe slot without recording an outcome
+func (b *CircuitBreakerClient) release(admitted admission) {
+	if !admitted.probe {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// record updates the breaker after a call; failed means the provider looked down.
+// Only the probe moves the breaker out of open or half-open. Calls admitted before
+// it last opened say nothing about the provider now, so they are ignored: a slow
+// success mustn't close it, and a late failure mustn't push the next probe back.
+func (b *CircuitBreakerClient) record(admitted admission, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if admitted.probe {
+		b.probing = false
+		if failed {
+			log.Printf("CircuitBreaker - %s probe failed, reopening", b.name)
+			b.open()
+			return
+		}
+		log.Printf("CircuitBreaker - %s recovered, closing", b.name)
+		b.state = BreakerClosed
+		b.failures = 0
+		return
+	}
+
+	if b.state != BreakerClosed || admitted.generation != b.generation {
+		return
+	}
+	if !failed {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.config.FailureThreshold {
+		log.Printf("CircuitBreaker - %s opened after %d consecutive failures", b.name, b.failures)
+		b.open()
+	}
+}
+
+// open starts failing fast, leaving behind calls admitted before now. Callers hold mu.
+func (b *CircuitBreakerClient) open() {
+	b.state = BreakerOpen
+	b.openedAt = time.Now()
+	b.generation++
+}
+
+// isOutage reports whether an error means the provider itself is failing, as
+// opposed to answering normally with "not found" or "not supported"
+func isOutage(err error) bool {
+	var openErr *CircuitOpenError
+	if errors.As(err, &openErr) {
+		return false
+	}
+	return errors.Is(err, ErrUpstreamUnavailable) || errors.Is(err, context.DeadlineExceeded)
+}
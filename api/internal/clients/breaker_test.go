@@ -0,0 +1,47 @@
+package clients
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreakerIgnoresCallsAdmittedBeforeItOpened(t *testing.T) {
+	for _, lateFailed := range []bool{false, true} {
+		b := NewCircuitBreakerClient("test", nil, BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Hour})
+
+		slow, err := b.allow()
+		if err != nil {
+			t.Fatal(err)
+		}
+		fast, _ := b.allow()
+		b.record(fast, true)
+		opened := b.Status()
+
+		// The slow call finishes after the breaker opened
+		b.record(slow, lateFailed)
+		if status := b.Status(); status.State != BreakerOpen || !status.OpenedAt.Equal(*opened.OpenedAt) {
+			t.Errorf("late call (failed: %v) left the breaker %s, opened at %v; want open since %v", lateFailed, status.State, status.OpenedAt, opened.OpenedAt)
+		}
+	}
+}
+
+func TestBreakerClosesOnlyOnProbeSuccess(t *testing.T) {
+	b := NewCircuitBreakerClient("test", nil, BreakerConfig{FailureThreshold: 1})
+	stale, _ := b.allow()
+	first, _ := b.allow()
+	b.record(first, true)
+
+	// OpenTimeout has passed, so the next call is the probe
+	probe, err := b.allow()
+	if err != nil || !probe.probe {
+		t.Fatalf("allow() = %+v, %v; want the probe", probe, err)
+	}
+	b.record(stale, false)
+	if state := b.Status().State; state != BreakerHalfOpen {
+		t.Fatalf("state after a stale success = %s, want %s", state, BreakerHalfOpen)
+	}
+	b.record(probe, false)
+	if state := b.Status().State; state != BreakerClosed {
+		t.Errorf("state after the probe succeeded = %s, want %s", state, BreakerClosed)
+	}
+}
@@ -0,0 +1,264 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/cole-zoom/dUW-app/api/internal/cache"
+	"github.com/cole-zoom/dUW-app/api/internal/market"
+	"github.com/cole-zoom/dUW-app/api/internal/models"
+)
+
+// CachePolicy controls how long each kind of response is kept
+type CachePolicy struct {
+	TickerDetailsTTL    time.Duration // Company reference data changes rarely
+	SuggestionsTTL      time.Duration // Ticker search results
+	OpenRangeTTL        time.Duration // Bars for ranges that include today, or empty results
+	ClosedRangeTTL      time.Duration // Bars for past days, which change only when a split re-adjusts them
+	CorporateActionsTTL time.Duration // Dividend and split histories, which only gain new announcements
+	NewsTTL             time.Duration // News pages; short so new articles show up promptly
+	FinancialsTTL       time.Duration // Financial statements, which change when a filing lands
+	MarketStatusTTL     time.Duration // Whether the market is open right now
+	MarketHolidaysTTL   time.Duration // Upcoming holidays; unscheduled closures are announced days ahead
+}
+
+// DefaultCachePolicy returns the TTLs used in production
+func DefaultCachePolicy() CachePolicy {
+	return CachePolicy{
+		TickerDetailsTTL:    72 * time.Hour,
+		SuggestionsTTL:      time.Hour,
+		OpenRangeTTL:        5 * time.Minute,
+		ClosedRangeTTL:      24 * time.Hour, // No longer than stored price history waits to check for splits
+		CorporateActionsTTL: 12 * time.Hour,
+		NewsTTL:             15 * time.Minute,
+		FinancialsTTL:       24 * time.Hour,
+		MarketStatusTTL:     time.Minute,
+		MarketHolidaysTTL:   6 * time.Hour,
+	}
+}
+
+// MethodCacheStats counts lookups for one client method
+type MethodCacheStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+}
+
+// CacheStats is a snapshot of hit/miss counters
+type CacheStats struct {
+	Hits    int64                       `json:"hits"`
+	Misses  int64                       `json:"misses"`
+	Methods map[string]MethodCacheStats `json:"methods"`
+}
+
+type methodCounters struct {
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// CachingClient is a read-through cache in front of another APIClient.
+// Responses are JSON-encoded into the store so any backend can hold them.
+type CachingClient struct {
+	next     APIClient
+	store    cache.Store
+	policy   CachePolicy
+	counters map[string]*methodCounters
+}
+
+// Cached method names, used in keys and stats
+const (
+	methodSuggestedStocks = "suggested_stocks"
+	methodListTickers     = "list_tickers"
+	methodAggregates      = "aggregates"
+	methodTickerDetails   = "ticker_details"
+	methodPreviousClose   = "previous_close"
+	methodGroupedDaily    = "grouped_daily"
+	methodDividends       = "dividends"
+	methodSplits          = "splits"
+	methodNews            = "news"
+	methodFinancials      = "financials"
+	methodMarketStatus    = "market_status"
+	methodMarketHolidays  = "market_holidays"
+)
+
+// NewCachingClient wraps next with a read-through cache
+func NewCachingClient(next APIClient, store cache.Store, policy CachePolicy) *CachingClient {
+	counters := make(map[string]*methodCounters)
+	for _, method := range []string{methodSuggestedStocks, methodListTickers, methodAggregates, methodTickerDetails, methodPreviousClose, methodGroupedDaily, methodDividends, methodSplits, methodNews, methodFinancials, methodMarketStatus, methodMarketHolidays} {
+		counters[method] = &methodCounters{}
+	}
+	return &CachingClient{
+		next:     next,
+		store:    store,
+		policy:   policy,
+		counters: counters,
+	}
+}
+
+// Stats returns a snapshot of the hit/miss counters
+func (c *CachingClient) Stats() CacheStats {
+	stats := CacheStats{Methods: make(map[string]MethodCacheStats, len(c.counters))}
+	for method, counter := range c.counters {
+		m := MethodCacheStats{Hits: counter.hits.Load(), Misses: counter.misses.Load()}
+		stats.Methods[method] = m
+		stats.Hits += m.Hits
+		stats.Misses += m.Misses
+	}
+	return stats
+}
+
+func (c *CachingClient) GetSuggestedStocks(ctx context.Context, query string) ([]models.DisplayStock, error) {
+	key := cacheKey(methodSuggestedStocks, strings.ToLower(query))
+	return readThrough(ctx, c, methodSuggestedStocks, key,
+		func() ([]models.DisplayStock, error) { return c.next.GetSuggestedStocks(ctx, query) },
+		func([]models.DisplayStock) time.Time { return time.Now().Add(c.policy.SuggestionsTTL) },
+	)
+}
+
+func (c *CachingClient) ListTickersPage(ctx context.Context, params models.TickerListParams) (*models.TickerPage, error) {
+	key := cacheKey(methodListTickers, strings.ToLower(params.Search), params.Market, params.Type, strconv.Itoa(params.Limit), params.Cursor)
+	return readThrough(ctx, c, methodListTickers, key,
+		func() (*models.TickerPage, error) { return c.next.ListTickersPage(ctx, params) },
+		func(*models.TickerPage) time.Time { return time.Now().Add(c.policy.SuggestionsTTL) },
+	)
+}
+
+// GetAggregates caches ranges that ended before today for a day. Closed bars only
+// change when a split re-adjusts them, and that needs to show up eventually.
+func (c *CachingClient) GetAggregates(ctx context.Context, ticker, multiplier, timespan, from, to string) (*models.AggregatesResponse, error) {
+	key := cacheKey(methodAggregates, strings.ToUpper(ticker), multiplier, timespan, from, to)
+	return readThrough(ctx, c, methodAggregates, key,
+		func() (*models.AggregatesResponse, error) {
+			return c.next.GetAggregates(ctx, ticker, multiplier, timespan, from, to)
+		},
+		func(resp *models.AggregatesResponse) time.Time {
+			return c.rangeExpiry(to, len(resp.Results) == 0)
+		},
+	)
+}
+
+func (c *CachingClient) GetTickerDetails(ctx context.Context, ticker string) (*models.TickerDetails, error) {
+	key := cacheKey(methodTickerDetails, strings.ToUpper(ticker))
+	return readThrough(ctx, c, methodTickerDetails, key,
+		func() (*models.TickerDetails, error) { return c.next.GetTickerDetails(ctx, ticker) },
+		func(*models.TickerDetails) time.Time { return time.Now().Add(c.policy.TickerDetailsTTL) },
+	)
+}
+
+// GetPreviousClose caches until the next session opens, when a new close becomes the previous one.
+func (c *CachingClient) GetPreviousClose(ctx context.Context, ticker string) (*models.PreviousCloseResponse, error) {
+	key := cacheKey(methodPreviousClose, strings.ToUpper(ticker))
+	return readThrough(ctx, c, methodPreviousClose, key,
+		func() (*models.PreviousCloseResponse, error) { return c.next.GetPreviousClose(ctx, ticker) },
+		func(*models.PreviousCloseResponse) time.Time { return market.NextOpen(time.Now()) },
+	)
+}
+
+func (c *CachingClient) GetGroupedDaily(ctx context.Context, date string) (*models.GroupedDailyResponse, error) {
+	key := cacheKey(methodGroupedDaily, date)
+	return readThrough(ctx, c, methodGroupedDaily, key,
+		func() (*models.GroupedDailyResponse, error) { return c.next.GetGroupedDaily(ctx, date) },
+		func(resp *models.GroupedDailyResponse) time.Time {
+			return c.rangeExpiry(date, len(resp.Results) == 0)
+		},
+	)
+}
+
+func (c *CachingClient) GetDividends(ctx context.Context, ticker string) (*models.DividendsResponse, error) {
+	key := cacheKey(methodDividends, strings.ToUpper(ticker))
+	return readThrough(ctx, c, methodDividends, key,
+		func() (*models.DividendsResponse, error) { return c.next.GetDividends(ctx, ticker) },
+		func(*models.DividendsResponse) time.Time { return time.Now().Add(c.policy.CorporateActionsTTL) },
+	)
+}
+
+func (c *CachingClient) GetSplits(ctx context.Context, ticker string) (*models.SplitsResponse, error) {
+	key := cacheKey(methodSplits, strings.ToUpper(ticker))
+	return readThrough(ctx, c, methodSplits, key,
+		func() (*models.SplitsResponse, error) { return c.next.GetSplits(ctx, ticker) },
+		func(*models.SplitsResponse) time.Time { return time.Now().Add(c.policy.CorporateActionsTTL) },
+	)
+}
+
+func (c *CachingClient) GetNews(ctx context.Context, params models.NewsParams) (*models.NewsPage, error) {
+	key := cacheKey(methodNews, strings.ToUpper(params.Ticker), strconv.Itoa(params.Limit), params.Cursor)
+	return readThrough(ctx, c, methodNews, key,
+		func() (*models.NewsPage, error) { return c.next.GetNews(ctx, params) },
+		func(*models.NewsPage) time.Time { return time.Now().Add(c.policy.NewsTTL) },
+	)
+}
+
+func (c *CachingClient) GetFinancials(ctx context.Context, ticker, timeframe string) (*models.FinancialsResponse, error) {
+	key := cacheKey(methodFinancials, strings.ToUpper(ticker), timeframe)
+	return readThrough(ctx, c, methodFinancials, key,
+		func() (*models.FinancialsResponse, error) { return c.next.GetFinancials(ctx, ticker, timeframe) },
+		func(*models.FinancialsResponse) time.Time { return time.Now().Add(c.policy.FinancialsTTL) },
+	)
+}
+
+func (c *CachingClient) GetMarketStatus(ctx context.Context) (*models.MarketStatusResponse, error) {
+	return readThrough(ctx, c, methodMarketStatus, cacheKey(methodMarketStatus),
+		func() (*models.MarketStatusResponse, error) { return c.next.GetMarketStatus(ctx) },
+		func(*models.MarketStatusResponse) time.Time { return time.Now().Add(c.policy.MarketStatusTTL) },
+	)
+}
+
+func (c *CachingClient) GetMarketHolidays(ctx context.Context) (*models.MarketHolidaysResponse, error) {
+	return readThrough(ctx, c, methodMarketHolidays, cacheKey(methodMarketHolidays),
+		func() (*models.MarketHolidaysResponse, error) { return c.next.GetMarketHolidays(ctx) },
+		func(*models.MarketHolidaysResponse) time.Time { return time.Now().Add(c.policy.MarketHolidaysTTL) },
+	)
+}
+
+// rangeExpiry keeps data for days before today for ClosedRangeTTL; adjusted prices
+// change after a split, so nothing is kept forever. Ranges touching today, and empty
+// results that may just not be published yet, get a short TTL.
+func (c *CachingClient) rangeExpiry(to string, empty bool) time.Time {
+	if to < market.Today() && !empty {
+		return time.Now().Add(c.policy.ClosedRangeTTL)
+	}
+	return time.Now().Add(c.policy.OpenRangeTTL)
+}
+
+// readThrough serves key from the store, or calls fetch and stores the result.
+// Store failures are logged and treated as misses so the cache never breaks a request.
+func readThrough[T any](ctx context.Context, c *CachingClient, method, key string, fetch func() (T, error), expiry func(T) time.Time) (T, error) {
+	counter := c.counters[method]
+
+	if data, ok, err := c.store.Get(ctx, key); err != nil {
+		log.Printf("Cache read failed for %s: %v", key, err)
+	} else if ok {
+		var cached T
+		if err := json.Unmarshal(data, &cached); err == nil {
+			counter.hits.Add(1)
+			return cached, nil
+		}
+		log.Printf("Cache entry for %s is corrupt, refetching", key)
+	}
+
+	counter.misses.Add(1)
+	value, err := fetch()
+	if err != nil {
+		return value, err
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("Cache encode failed for %s: %v", key, err)
+		return value, nil
+	}
+	if err := c.store.Set(ctx, key, data, expiry(value)); err != nil {
+		log.Printf("Cache write failed for %s: %v", key, err)
+	}
+	return value, nil
+}
+
+// cacheKey joins a method name and its parameters into a store key
+func cacheKey(method string, params ...string) string {
+	return fmt.Sprintf("%s:%s", method, strings.Join(params, ":"))
+}
@@ -9,7 +9,15 @@ import (
 // APIClient defines the interface for an external stock API.
 type APIClient interface {
 	GetSuggestedStocks(ctx context.Context, query string) ([]models.DisplayStock, error)
+	ListTickersPage(ctx context.Context, params models.TickerListParams) (*models.TickerPage, error)
 	GetAggregates(ctx context.Context, ticker, multiplier, timespan, from, to string) (*models.AggregatesResponse, error)
 	GetTickerDetails(ctx context.Context, ticker string) (*models.TickerDetails, error)
 	GetPreviousClose(ctx context.Context, ticker string) (*models.PreviousCloseResponse, error)
+	GetGroupedDaily(ctx context.Context, date string) (*models.GroupedDailyResponse, error)
+	GetDividends(ctx context.Context, ticker string) (*models.DividendsResponse, error)
+	GetSplits(ctx context.Context, ticker string) (*models.SplitsResponse, error)
+	GetNews(ctx context.Context, params models.NewsParams) (*models.NewsPage, error)
+	GetFinancials(ctx context.Context, ticker, timeframe string) (*models.FinancialsResponse, error)
+	GetMarketStatus(ctx context.Context) (*models.MarketStatusResponse, error)
+	GetMarketHolidays(ctx context.Context) (*models.MarketHolidaysResponse, error)
 }
@@ -0,0 +1,82 @@
+package clients
+
+import (
+	"context"
+	"sync"
+)
+
+// coalescer deduplicates identical in-flight calls so concurrent callers share
+// one upstream request (and one rate-limit token). Unlike a plain singleflight,
+// each caller waits on its own context: a caller that gives up returns
+// immediately, and the shared call is only cancelled once every caller has left.
+type coalescer struct {
+	mu    sync.Mutex
+	calls map[string]*inflightCall
+}
+
+type inflightCall struct {
+	done    chan struct{}
+	value   any
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+func newCoalescer() *coalescer {
+	return &coalescer{calls: make(map[string]*inflightCall)}
+}
+
+// coalesce runs fn once per key among concurrent callers and hands every caller
+// the same result. Callers must treat the returned value as read-only.
+func coalesce[T any](ctx context.Context, c *coalescer, key string, fn func(ctx context.Context) (T, error)) (T, error) {
+	c.mu.Lock()
+	call, ok := c.calls[key]
+	if !ok {
+		// The shared call keeps the first caller's values but not its cancellation.
+		// Its deadline is kept only for rate limit admission.
+		baseCtx := context.WithoutCancel(ctx)
+		if deadline, ok := ctx.Deadline(); ok {
+			baseCtx = withAdmissionDeadline(baseCtx, deadline)
+		}
+		callCtx, cancel := context.WithCancel(baseCtx)
+		call = &inflightCall{done: make(chan struct{}), cancel: cancel}
+		c.calls[key] = call
+
+		go func() {
+			call.value, call.err = fn(callCtx)
+			cancel()
+
+			c.mu.Lock()
+			if c.calls[key] == call {
+				delete(c.calls, key)
+			}
+			c.mu.Unlock()
+			close(call.done)
+		}()
+	}
+	call.waiters++
+	c.mu.Unlock()
+
+	select {
+	case <-call.done:
+		if call.err != nil {
+			var zero T
+			return zero, call.err
+		}
+		return call.value.(T), nil
+	case <-ctx.Done():
+		c.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			// Nobody is left to use the result; stop the upstream request and let
+			// the next caller start a fresh one.
+			call.cancel()
+			if c.calls[key] == call {
+				delete(c.calls, key)
+			}
+		}
+		c.mu.Unlock()
+		var zero T
+		return zero, ctx.Err()
+	}
+}
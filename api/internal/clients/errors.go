@@ -0,0 +1,80 @@
+package clients
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Error categories every provider maps its failures onto. Check them with errors.Is.
+var (
+	ErrRateLimited         = errors.New("market data provider rate limit exceeded")
+	ErrUnauthorized        = errors.New("market data provider rejected the API key")
+	ErrForbidden           = errors.New("market data provider plan doesn't cover this request")
+	ErrNotFound            = errors.New("market data not found")
+	ErrUpstreamUnavailable = errors.New("market data provider unavailable")
+)
+
+// ErrAdmissionRejected is returned (wrapped) when a request is turned away before
+// reaching the provider because its wait for a rate limit slot would outlast the
+// caller's deadline. The provider itself is healthy.
+var ErrAdmissionRejected = errors.New("request would wait past its deadline for a rate limit slot")
+
+// ErrUnsupported is returned (wrapped) when a provider has no equivalent for a method
+var ErrUnsupported = errors.New("not supported by this market data provider")
+
+// StatusError is returned when a provider answers with an unexpected HTTP status.
+// It unwraps to the matching error category.
+type StatusError struct {
+	StatusCode int
+	RetryAfter time.Duration // From the Retry-After header; zero if absent
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API request failed with status code: %d", e.StatusCode)
+}
+
+func (e *StatusError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusForbidden:
+		// A valid key whose plan doesn't include the endpoint or the data asked for
+		return ErrForbidden
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode >= 500:
+		return ErrUpstreamUnavailable
+	default:
+		return nil
+	}
+}
+
+// TickerNotFoundError is returned when a ticker doesn't exist in the provider's database.
+type TickerNotFoundError struct {
+	Ticker string
+}
+
+func (e *TickerNotFoundError) Error() string {
+	return fmt.Sprintf("ticker not found: %s", e.Ticker)
+}
+
+func (e *TickerNotFoundError) Unwrap() error {
+	return ErrNotFound
+}
+
+// RetryAfter returns how long the provider (or an open circuit breaker) asked callers to wait, or zero
+func RetryAfter(err error) time.Duration {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.RetryAfter
+	}
+	var openErr *CircuitOpenError
+	if errors.As(err, &openErr) {
+		return openErr.RetryAfter
+	}
+	return 0
+}
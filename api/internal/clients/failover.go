@@ -0,0 +1,343 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cole-zoom/dUW-app/api/internal/models"
+)
+
+// NamedProvider pairs a provider with the name recorded as the source of its responses
+type NamedProvider struct {
+	Name   string
+	Client APIClient
+}
+
+// ProviderHealth reports whether a provider is currently being skipped
+type ProviderHealth struct {
+	Name          string     `json:"name"`
+	Healthy       bool       `json:"healthy"`
+	CoolingUntil  *time.Time `json:"cooling_until,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	ServedCount   int64      `json:"served"`
+	FailoverCount int64      `json:"failovers"`
+}
+
+type providerState struct {
+	coolingUntil time.Time
+	lastError    string
+	served       int64
+	failovers    int64
+}
+
+type noFailoverKey struct{}
+
+// WithoutFailover sends calls made with ctx only to the highest priority provider,
+// even while it is cooling down, for callers that can't mix data from different
+// providers, such as a sync that deactivates whatever a listing leaves out
+func WithoutFailover(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noFailoverKey{}, true)
+}
+
+// FailoverClient tries providers in priority order, falling back when one is
+// rate limited, erroring server-side, timing out, or lacks the method. A provider
+// that fails that way is skipped for the cool-down period.
+type FailoverClient struct {
+	providers []NamedProvider
+	cooldown  time.Duration
+
+	mu     sync.Mutex
+	states map[string]*providerState
+}
+
+// NewFailoverClient creates a chain over providers, highest priority first
+func NewFailoverClient(providers []NamedProvider, cooldown time.Duration) *FailoverClient {
+	states := make(map[string]*providerState, len(providers))
+	for _, p := range providers {
+		states[p.Name] = &providerState{}
+	}
+	return &FailoverClient{
+		providers: providers,
+		cooldown:  cooldown,
+		states:    states,
+	}
+}
+
+// Health returns each provider's state in priority order
+func (c *FailoverClient) Health() []ProviderHealth {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	health := make([]ProviderHealth, 0, len(c.providers))
+	for _, p := range c.providers {
+		state := c.states[p.Name]
+		h := ProviderHealth{
+			Name:          p.Name,
+			Healthy:       !now.Before(state.coolingUntil),
+			LastError:     state.lastError,
+			ServedCount:   state.served,
+			FailoverCount: state.failovers,
+		}
+		if !h.Healthy {
+			until := state.coolingUntil
+			h.CoolingUntil = &until
+		}
+		health = append(health, h)
+	}
+	return health
+}
+
+func (c *FailoverClient) GetSuggestedStocks(ctx context.Context, query string) ([]models.DisplayStock, error) {
+	return failover(ctx, c, "GetSuggestedStocks",
+		func(client APIClient) ([]models.DisplayStock, error) { return client.GetSuggestedStocks(ctx, query) },
+		nil,
+	)
+}
+
+// ListTickersPage fails over only for the first page. Cursors are prefixed with
+// the provider that issued them so later pages go back to the same provider.
+func (c *FailoverClient) ListTickersPage(ctx context.Context, params models.TickerListParams) (*models.TickerPage, error) {
+	stamp := func(page *models.TickerPage, source string) {
+		page.Source = source
+		if page.NextCursor != "" {
+			page.NextCursor = source + ":" + page.NextCursor
+		}
+	}
+
+	if params.Cursor != "" {
+		source, cursor, _ := strings.Cut(params.Cursor, ":")
+		p, ok := c.provider(source)
+		if !ok {
+			return nil, fmt.Errorf("cursor was issued by unknown provider %q", source)
+		}
+		params.Cursor = cursor
+		page, err := p.Client.ListTickersPage(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		stamp(page, p.Name)
+		return page, nil
+	}
+
+	return failover(ctx, c, "ListTickersPage",
+		func(client APIClient) (*models.TickerPage, error) { return client.ListTickersPage(ctx, params) },
+		stamp,
+	)
+}
+
+func (c *FailoverClient) GetAggregates(ctx context.Context, ticker, multiplier, timespan, from, to string) (*models.AggregatesResponse, error) {
+	return failover(ctx, c, "GetAggregates",
+		func(client APIClient) (*models.AggregatesResponse, error) {
+			return client.GetAggregates(ctx, ticker, multiplier, timespan, from, to)
+		},
+		func(resp *models.AggregatesResponse, source string) { resp.Source = source },
+	)
+}
+
+func (c *FailoverClient) GetTickerDetails(ctx context.Context, ticker string) (*models.TickerDetails, error) {
+	return failover(ctx, c, "GetTickerDetails",
+		func(client APIClient) (*models.TickerDetails, error) { return client.GetTickerDetails(ctx, ticker) },
+		func(details *models.TickerDetails, source string) { details.Source = source },
+	)
+}
+
+func (c *FailoverClient) GetPreviousClose(ctx context.Context, ticker string) (*models.PreviousCloseResponse, error) {
+	return failover(ctx, c, "GetPreviousClose",
+		func(client APIClient) (*models.PreviousCloseResponse, error) {
+			return client.GetPreviousClose(ctx, ticker)
+		},
+		func(resp *models.PreviousCloseResponse, source string) { resp.Source = source },
+	)
+}
+
+func (c *FailoverClient) GetGroupedDaily(ctx context.Context, date string) (*models.GroupedDailyResponse, error) {
+	return failover(ctx, c, "GetGroupedDaily",
+		func(client APIClient) (*models.GroupedDailyResponse, error) { return client.GetGroupedDaily(ctx, date) },
+		func(resp *models.GroupedDailyResponse, source string) { resp.Source = source },
+	)
+}
+
+func (c *FailoverClient) GetDividends(ctx context.Context, ticker string) (*models.DividendsResponse, error) {
+	return failover(ctx, c, "GetDividends",
+		func(client APIClient) (*models.DividendsResponse, error) { return client.GetDividends(ctx, ticker) },
+		func(resp *models.DividendsResponse, source string) { resp.Source = source },
+	)
+}
+
+func (c *FailoverClient) GetSplits(ctx context.Context, ticker string) (*models.SplitsResponse, error) {
+	return failover(ctx, c, "GetSplits",
+		func(client APIClient) (*models.SplitsResponse, error) { return client.GetSplits(ctx, ticker) },
+		func(resp *models.SplitsResponse, source string) { resp.Source = source },
+	)
+}
+
+func (c *FailoverClient) GetFinancials(ctx context.Context, ticker, timeframe string) (*models.FinancialsResponse, error) {
+	return failover(ctx, c, "GetFinancials",
+		func(client APIClient) (*models.FinancialsResponse, error) {
+			return client.GetFinancials(ctx, ticker, timeframe)
+		},
+		func(resp *models.FinancialsResponse, source string) { resp.Source = source },
+	)
+}
+
+func (c *FailoverClient) GetMarketStatus(ctx context.Context) (*models.MarketStatusResponse, error) {
+	return failover(ctx, c, "GetMarketStatus",
+		func(client APIClient) (*models.MarketStatusResponse, error) { return client.GetMarketStatus(ctx) },
+		func(resp *models.MarketStatusResponse, source string) { resp.Source = source },
+	)
+}
+
+func (c *FailoverClient) GetMarketHolidays(ctx context.Context) (*models.MarketHolidaysResponse, error) {
+	return failover(ctx, c, "GetMarketHolidays",
+		func(client APIClient) (*models.MarketHolidaysResponse, error) { return client.GetMarketHolidays(ctx) },
+		func(resp *models.MarketHolidaysResponse, source string) { resp.Source = source },
+	)
+}
+
+// GetNews fails over only for the first page; like ListTickersPage, cursors name
+// the provider that issued them.
+func (c *FailoverClient) GetNews(ctx context.Context, params models.NewsParams) (*models.NewsPage, error) {
+	stamp := func(page *models.NewsPage, source string) {
+		page.Source = source
+		if page.NextCursor != "" {
+			page.NextCursor = source + ":" + page.NextCursor
+		}
+	}
+
+	if params.Cursor != "" {
+		source, cursor, _ := strings.Cut(params.Cursor, ":")
+		p, ok := c.provider(source)
+		if !ok {
+			return nil, fmt.Errorf("cursor was issued by unknown provider %q", source)
+		}
+		params.Cursor = cursor
+		page, err := p.Client.GetNews(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		stamp(page, p.Name)
+		return page, nil
+	}
+
+	return failover(ctx, c, "GetNews",
+		func(client APIClient) (*models.NewsPage, error) { return client.GetNews(ctx, params) },
+		stamp,
+	)
+}
+
+// failover calls fetch on each available provider until one succeeds or fails
+// in a way another provider can't fix (e.g. an unknown ticker). Providers that
+// are cooling down are tried last rather than never, so a chain whose providers
+// are all unhealthy still makes an attempt.
+func failover[T any](ctx context.Context, c *FailoverClient, method string, fetch func(APIClient) (T, error), stamp func(T, string)) (T, error) {
+	var zero T
+	var errs []error
+
+	providers := c.orderedProviders()
+	if pinned, _ := ctx.Value(noFailoverKey{}).(bool); pinned && len(c.providers) > 0 {
+		providers = c.providers[:1]
+	}
+	for _, p := range providers {
+		value, err := fetch(p.Client)
+		if err == nil {
+			c.recordSuccess(p.Name)
+			if stamp != nil {
+				stamp(value, p.Name)
+			}
+			return value, nil
+		}
+
+		// Stop if the caller gave up or the error isn't the provider's fault
+		if ctx.Err() != nil || !shouldFailover(err) {
+			return zero, err
+		}
+
+		log.Printf("FailoverClient - %s failed on %s, trying next provider: %v", method, p.Name, err)
+		c.recordFailure(p.Name, err, isProviderOutage(err))
+		errs = append(errs, fmt.Errorf("%s: %w", p.Name, err))
+	}
+
+	if len(errs) == 0 {
+		return zero, fmt.Errorf("no market data providers configured")
+	}
+	return zero, fmt.Errorf("all market data providers failed: %w", errors.Join(errs...))
+}
+
+// provider looks up a provider by name, e.g. the one that issued a cursor, and
+// reports whether it is part of the chain
+func (c *FailoverClient) provider(name string) (NamedProvider, bool) {
+	for _, p := range c.providers {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return NamedProvider{}, false
+}
+
+// orderedProviders returns healthy providers first, then those cooling down, each
+// group in priority order
+func (c *FailoverClient) orderedProviders() []NamedProvider {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	ordered := make([]NamedProvider, 0, len(c.providers))
+	var cooling []NamedProvider
+	for _, p := range c.providers {
+		if now.Before(c.states[p.Name].coolingUntil) {
+			cooling = append(cooling, p)
+		} else {
+			ordered = append(ordered, p)
+		}
+	}
+	return append(ordered, cooling...)
+}
+
+func (c *FailoverClient) recordSuccess(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	state := c.states[name]
+	state.served++
+	state.coolingUntil = time.Time{}
+}
+
+// recordFailure counts a failover and, for outages rather than missing features, starts a cool-down
+func (c *FailoverClient) recordFailure(name string, err error, coolDown bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	state := c.states[name]
+	state.failovers++
+	state.lastError = err.Error()
+	if coolDown {
+		state.coolingUntil = time.Now().Add(c.cooldown)
+	}
+}
+
+// isProviderOutage reports whether a failure that warranted failing over was the
+// provider being unhealthy, as opposed to a missing feature or our own rate limiter
+// turning the request away, and so whether the provider should cool down
+func isProviderOutage(err error) bool {
+	return !errors.Is(err, ErrUnsupported) && !errors.Is(err, ErrForbidden) && !errors.Is(err, ErrAdmissionRejected)
+}
+
+// shouldFailover reports whether another provider might succeed where this one failed:
+// rate limits (the provider's or our own queue for it), outages and timeouts, rejected
+// keys, and methods or data the provider (or our plan with it) doesn't offer.
+func shouldFailover(err error) bool {
+	if errors.Is(err, ErrUnsupported) || errors.Is(err, ErrForbidden) || errors.Is(err, ErrAdmissionRejected) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	if errors.Is(err, ErrRateLimited) || errors.Is(err, ErrUpstreamUnavailable) || errors.Is(err, ErrUnauthorized) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,589 @@
+package clients
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cole-zoom/dUW-app/api/internal/market"
+	"github.com/cole-zoom/dUW-app/api/internal/models"
+)
+
+// finnhubTypes maps Finnhub security types onto the Polygon type codes the rest of the app uses
+var finnhubTypes = map[string]string{
+	"Common Stock":    "CS",
+	"ETP":             "ETF",
+	"ADR":             "ADRC",
+	"REIT":            "CS",
+	"Preference":      "PFD",
+	"Unit":            "UNIT",
+	"Right":           "RIGHT",
+	"Warrant":         "WARRANT",
+	"Closed-End Fund": "FUND",
+}
+
+// FinnhubClient implements APIClient against Finnhub's REST API, mapping its
+// payloads into the Polygon-shaped models the services already understand.
+type FinnhubClient struct {
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+	scheduler  *Scheduler
+	retry      RetryPolicy
+	inflight   *coalescer
+}
+
+// FinnhubConfig describes the Finnhub plan in use
+type FinnhubConfig struct {
+	APIKey            string
+	RequestsPerMinute int
+	Burst             int
+	BaseURL           string
+	Timeout           time.Duration
+}
+
+// DefaultFinnhubConfig returns the free tier limits; zero fields in a config passed
+// to NewFinnhubClient fall back to these
+func DefaultFinnhubConfig() FinnhubConfig {
+	return FinnhubConfig{
+		// Finnhub free tier: 60 requests/minute
+		RequestsPerMinute: 60,
+		Burst:             10,
+		BaseURL:           "https://finnhub.io/api/v1",
+		Timeout:           10 * time.Second,
+	}
+}
+
+func NewFinnhubClient(config FinnhubConfig) *FinnhubClient {
+	defaults := DefaultFinnhubConfig()
+	if config.RequestsPerMinute <= 0 {
+		config.RequestsPerMinute = defaults.RequestsPerMinute
+	}
+	if config.Burst <= 0 {
+		config.Burst = defaults.Burst
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = defaults.BaseURL
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+
+	return &FinnhubClient{
+		httpClient: &http.Client{Timeout: config.Timeout},
+		baseURL:    strings.TrimRight(config.BaseURL, "/"),
+		apiKey:     config.APIKey,
+		scheduler:  NewScheduler(time.Minute/time.Duration(config.RequestsPerMinute), config.Burst),
+		retry:      DefaultRetryPolicy(),
+		inflight:   newCoalescer(),
+	}
+}
+
+// RateLimitStats reports the request queue in front of Finnhub
+func (c *FinnhubClient) RateLimitStats() []SchedulerStats {
+	return []SchedulerStats{c.scheduler.Stats()}
+}
+
+// get requests a Finnhub endpoint, retrying transient failures, and decodes the JSON body into out.
+// Every attempt waits on the rate limiter.
+func (c *FinnhubClient) get(ctx context.Context, path string, params url.Values, out any) error {
+	params.Set("token", c.apiKey)
+	apiURL := fmt.Sprintf("%s%s?%s", c.baseURL, path, params.Encode())
+	log.Printf("Making API request to: %s%s", c.baseURL, path) // Don't log API key
+
+	wait := func(ctx context.Context) error {
+		if err := c.scheduler.Wait(ctx); err != nil {
+			return fmt.Errorf("rate limit wait failed: %w", err)
+		}
+		return nil
+	}
+	return fetchJSON(ctx, c.httpClient, c.retry, wait, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	}, out)
+}
+
+// finnhubSymbol is one entry of Finnhub's symbol listing
+type finnhubSymbol struct {
+	Symbol      string `json:"symbol"`
+	Description string `json:"description"`
+	Type        string `json:"type"`
+	Currency    string `json:"currency"`
+	MIC         string `json:"mic"`
+	FIGI        string `json:"figi"`
+}
+
+// finnhubSearchResponse is the response from /search
+type finnhubSearchResponse struct {
+	Count  int             `json:"count"`
+	Result []finnhubSymbol `json:"result"`
+}
+
+// finnhubCandles is the response from /stock/candle, one parallel array per field
+type finnhubCandles struct {
+	Status    string    `json:"s"` // "ok" or "no_data"
+	Open      []float64 `json:"o"`
+	High      []float64 `json:"h"`
+	Low       []float64 `json:"l"`
+	Close     []float64 `json:"c"`
+	Volume    []float64 `json:"v"`
+	Timestamp []int64   `json:"t"` // Unix seconds
+}
+
+// finnhubProfile is the response from /stock/profile2
+type finnhubProfile struct {
+	Ticker               string  `json:"ticker"`
+	Name                 string  `json:"name"`
+	Exchange             string  `json:"exchange"`
+	Country              string  `json:"country"`
+	Currency             string  `json:"currency"`
+	IPO                  string  `json:"ipo"`
+	MarketCapitalization float64 `json:"marketCapitalization"` // Millions
+	ShareOutstanding     float64 `json:"shareOutstanding"`     // Millions
+	WebURL               string  `json:"weburl"`
+}
+
+func (c *FinnhubClient) GetSuggestedStocks(ctx context.Context, query string) ([]models.DisplayStock, error) {
+	page, err := c.ListTickersPage(ctx, models.TickerListParams{Search: query, Market: "stocks"})
+	if err != nil {
+		return nil, err
+	}
+	return models.ToDisplayStocks(page.Results), nil
+}
+
+// ListTickersPage searches Finnhub when params.Search is set and otherwise lists
+// every US symbol. Finnhub returns listings in one response, so pages and cursors
+// (a result offset) are sliced out locally.
+func (c *FinnhubClient) ListTickersPage(ctx context.Context, params models.TickerListParams) (*models.TickerPage, error) {
+	key := cacheKey(methodListTickers, params.Search, params.Market, params.Type, strconv.Itoa(params.Limit), params.Cursor)
+	return coalesce(ctx, c.inflight, key, func(ctx context.Context) (*models.TickerPage, error) {
+		return c.fetchTickersPage(ctx, params)
+	})
+}
+
+// fetchTickersPage performs the HTTP request behind ListTickersPage.
+func (c *FinnhubClient) fetchTickersPage(ctx context.Context, listParams models.TickerListParams) (*models.TickerPage, error) {
+	if listParams.Market != "" && listParams.Market != "stocks" {
+		return nil, fmt.Errorf("finnhub market %q: %w", listParams.Market, ErrUnsupported)
+	}
+	log.Printf("ListTickersPage called with search: '%s', cursor: %t", listParams.Search, listParams.Cursor != "")
+
+	var symbols []finnhubSymbol
+	if listParams.Search != "" {
+		var resp finnhubSearchResponse
+		if err := c.get(ctx, "/search", url.Values{"q": {listParams.Search}, "exchange": {"US"}}, &resp); err != nil {
+			return nil, err
+		}
+		symbols = resp.Result
+	} else {
+		if err := c.get(ctx, "/stock/symbol", url.Values{"exchange": {"US"}}, &symbols); err != nil {
+			return nil, err
+		}
+		slices.SortFunc(symbols, func(a, b finnhubSymbol) int { return strings.Compare(a.Symbol, b.Symbol) })
+	}
+
+	tickers := make([]models.PolygonTickerResponse, 0, len(symbols))
+	for _, symbol := range symbols {
+		ticker := symbol.toTicker()
+		if listParams.Type != "" && ticker.Type != listParams.Type {
+			continue
+		}
+		tickers = append(tickers, ticker)
+	}
+
+	limit := listParams.Limit
+	if limit <= 0 || limit > 1000 {
+		limit = 1000
+	}
+	offset := 0
+	if listParams.Cursor != "" {
+		parsed, err := strconv.Atoi(listParams.Cursor)
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("invalid cursor %q", listParams.Cursor)
+		}
+		offset = parsed
+	}
+
+	page := &models.TickerPage{Results: tickers[min(offset, len(tickers)):min(offset+limit, len(tickers))]}
+	if offset+limit < len(tickers) {
+		page.NextCursor = strconv.Itoa(offset + limit)
+	}
+	return page, nil
+}
+
+// toTicker maps a Finnhub symbol onto the Polygon ticker shape
+func (s finnhubSymbol) toTicker() models.PolygonTickerResponse {
+	return models.PolygonTickerResponse{
+		Ticker:          s.Symbol,
+		Name:            s.Description,
+		Market:          "stocks",
+		Locale:          "us",
+		PrimaryExchange: s.MIC,
+		Type:            finnhubTypes[s.Type],
+		Active:          true,
+		CurrencyName:    strings.ToLower(s.Currency),
+		CompositeFigi:   s.FIGI,
+	}
+}
+
+// GetAggregates fetches candles for a ticker and converts them into Polygon-style bars.
+// Finnhub resolutions cover minute multiples up to an hour, plus day, week, and month.
+func (c *FinnhubClient) GetAggregates(ctx context.Context, ticker, multiplier, timespan, from, to string) (*models.AggregatesResponse, error) {
+	return coalesce(ctx, c.inflight, cacheKey(methodAggregates, ticker, multiplier, timespan, from, to), func(ctx context.Context) (*models.AggregatesResponse, error) {
+		return c.fetchAggregates(ctx, ticker, multiplier, timespan, from, to)
+	})
+}
+
+// fetchAggregates performs the HTTP request behind GetAggregates.
+func (c *FinnhubClient) fetchAggregates(ctx context.Context, ticker, multiplier, timespan, from, to string) (*models.AggregatesResponse, error) {
+	log.Printf("GetAggregates called for ticker: %s, timespan: %s, from: %s, to: %s", ticker, timespan, from, to)
+
+	resolution, err := finnhubResolution(multiplier, timespan)
+	if err != nil {
+		return nil, err
+	}
+	start, err := time.ParseInLocation("2006-01-02", from, market.Location())
+	if err != nil {
+		return nil, fmt.Errorf("invalid from date %q: %w", from, err)
+	}
+	end, err := time.ParseInLocation("2006-01-02", to, market.Location())
+	if err != nil {
+		return nil, fmt.Errorf("invalid to date %q: %w", to, err)
+	}
+
+	bars, err := c.candles(ctx, ticker, resolution, start, end.AddDate(0, 0, 1).Add(-time.Second))
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("GetAggregates Response: ResultsCount=%d", len(bars))
+	return &models.AggregatesResponse{
+		Ticker:       ticker,
+		QueryCount:   len(bars),
+		ResultsCount: len(bars),
+		Adjusted:     true,
+		Results:      bars,
+		Status:       "OK",
+	}, nil
+}
+
+// candles fetches bars between two instants; "no_data" is an empty result, not an error
+func (c *FinnhubClient) candles(ctx context.Context, ticker, resolution string, start, end time.Time) ([]models.AggregateBar, error) {
+	params := url.Values{
+		"symbol":     {ticker},
+		"resolution": {resolution},
+		"from":       {strconv.FormatInt(start.Unix(), 10)},
+		"to":         {strconv.FormatInt(end.Unix(), 10)},
+	}
+	var resp finnhubCandles
+	if err := c.get(ctx, "/stock/candle", params, &resp); err != nil {
+		return nil, err
+	}
+
+	bars := make([]models.AggregateBar, 0, len(resp.Timestamp))
+	if resp.Status != "ok" {
+		return bars, nil
+	}
+	for i, ts := range resp.Timestamp {
+		if i >= len(resp.Open) || i >= len(resp.High) || i >= len(resp.Low) || i >= len(resp.Close) || i >= len(resp.Volume) {
+			break
+		}
+		bars = append(bars, models.AggregateBar{
+			Open:      resp.Open[i],
+			High:      resp.High[i],
+			Low:       resp.Low[i],
+			Close:     resp.Close[i],
+			Volume:    resp.Volume[i],
+			Timestamp: models.FlexibleInt64(ts * 1000),
+		})
+	}
+	return bars, nil
+}
+
+// finnhubResolution converts a Polygon multiplier/timespan pair into a Finnhub candle resolution
+func finnhubResolution(multiplier, timespan string) (string, error) {
+	n, err := strconv.Atoi(multiplier)
+	if err != nil || n < 1 {
+		return "", fmt.Errorf("invalid multiplier %q", multiplier)
+	}
+
+	switch timespan {
+	case "minute", "hour":
+		minutes := n
+		if timespan == "hour" {
+			minutes = n * 60
+		}
+		if !slices.Contains([]int{1, 5, 15, 30, 60}, minutes) {
+			return "", fmt.Errorf("finnhub %d-minute bars: %w", minutes, ErrUnsupported)
+		}
+		return strconv.Itoa(minutes), nil
+	case "day", "week", "month":
+		if n != 1 {
+			return "", fmt.Errorf("finnhub %s bars with multiplier %d: %w", timespan, n, ErrUnsupported)
+		}
+		return map[string]string{"day": "D", "week": "W", "month": "M"}[timespan], nil
+	default:
+		return "", fmt.Errorf("finnhub timespan %q: %w", timespan, ErrUnsupported)
+	}
+}
+
+// GetTickerDetails fetches a company profile and maps it onto TickerDetails.
+func (c *FinnhubClient) GetTickerDetails(ctx context.Context, ticker string) (*models.TickerDetails, error) {
+	return coalesce(ctx, c.inflight, cacheKey(methodTickerDetails, ticker), func(ctx context.Context) (*models.TickerDetails, error) {
+		return c.fetchTickerDetails(ctx, ticker)
+	})
+}
+
+// fetchTickerDetails performs the HTTP request behind GetTickerDetails.
+func (c *FinnhubClient) fetchTickerDetails(ctx context.Context, ticker string) (*models.TickerDetails, error) {
+	log.Printf("GetTickerDetails called for ticker: %s", ticker)
+
+	var profile finnhubProfile
+	if err := c.get(ctx, "/stock/profile2", url.Values{"symbol": {ticker}}, &profile); err != nil {
+		return nil, err
+	}
+	// Finnhub answers unknown symbols with an empty object rather than a 404
+	if profile.Ticker == "" {
+		return nil, &TickerNotFoundError{Ticker: ticker}
+	}
+
+	return &models.TickerDetails{
+		Ticker:                      profile.Ticker,
+		Name:                        profile.Name,
+		Market:                      "stocks",
+		Locale:                      strings.ToLower(profile.Country),
+		PrimaryExchange:             profile.Exchange,
+		Type:                        "CS",
+		Active:                      true,
+		CurrencyName:                strings.ToLower(profile.Currency),
+		MarketCap:                   profile.MarketCapitalization * 1e6,
+		ShareClassSharesOutstanding: int64(profile.ShareOutstanding * 1e6),
+		WeightedSharesOutstanding:   int64(profile.ShareOutstanding * 1e6),
+		HomepageURL:                 profile.WebURL,
+		ListDate:                    profile.IPO,
+	}, nil
+}
+
+// GetPreviousClose returns the most recent daily bar from a session before today.
+func (c *FinnhubClient) GetPreviousClose(ctx context.Context, ticker string) (*models.PreviousCloseResponse, error) {
+	return coalesce(ctx, c.inflight, cacheKey(methodPreviousClose, ticker), func(ctx context.Context) (*models.PreviousCloseResponse, error) {
+		return c.fetchPreviousClose(ctx, ticker)
+	})
+}
+
+// fetchPreviousClose performs the HTTP request behind GetPreviousClose.
+func (c *FinnhubClient) fetchPreviousClose(ctx context.Context, ticker string) (*models.PreviousCloseResponse, error) {
+	log.Printf("GetPreviousClose called for ticker: %s", ticker)
+
+	now := time.Now().In(market.Location())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	// Ten calendar days always spans at least one full session
+	bars, err := c.candles(ctx, ticker, "D", today.AddDate(0, 0, -10), today.Add(-time.Second))
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.PreviousCloseResponse{
+		Ticker:   ticker,
+		Adjusted: true,
+		Results:  []models.AggregateBar{},
+		Status:   "OK",
+	}
+	if len(bars) > 0 {
+		bar := bars[len(bars)-1]
+		bar.Ticker = ticker
+		response.Results = append(response.Results, bar)
+		response.QueryCount, response.ResultsCount = 1, 1
+	}
+
+	log.Printf("GetPreviousClose Response: ResultsCount=%d", response.ResultsCount)
+	return response, nil
+}
+
+// GetGroupedDaily is not offered by Finnhub; callers fall back to per-ticker prices.
+func (c *FinnhubClient) GetGroupedDaily(ctx context.Context, date string) (*models.GroupedDailyResponse, error) {
+	return nil, fmt.Errorf("finnhub grouped daily bars: %w", ErrUnsupported)
+}
+
+// GetDividends is not available on Finnhub's free tier.
+func (c *FinnhubClient) GetDividends(ctx context.Context, ticker string) (*models.DividendsResponse, error) {
+	return nil, fmt.Errorf("finnhub dividends: %w", ErrUnsupported)
+}
+
+// GetFinancials is not offered by Finnhub in a form we can map onto statements.
+func (c *FinnhubClient) GetFinancials(ctx context.Context, ticker, timeframe string) (*models.FinancialsResponse, error) {
+	return nil, fmt.Errorf("finnhub financials: %w", ErrUnsupported)
+}
+
+// GetSplits is not available on Finnhub's free tier.
+func (c *FinnhubClient) GetSplits(ctx context.Context, ticker string) (*models.SplitsResponse, error) {
+	return nil, fmt.Errorf("finnhub splits: %w", ErrUnsupported)
+}
+
+// finnhubArticle is an entry from Finnhub's /company-news endpoint
+type finnhubArticle struct {
+	ID       int64  `json:"id"`
+	Datetime int64  `json:"datetime"` // Unix seconds
+	Headline string `json:"headline"`
+	Image    string `json:"image"`
+	Related  string `json:"related"` // Comma-separated tickers
+	Source   string `json:"source"`
+	Summary  string `json:"summary"`
+	URL      string `json:"url"`
+}
+
+func (a finnhubArticle) toArticle() models.NewsArticle {
+	article := models.NewsArticle{
+		ID:           "finnhub-" + strconv.FormatInt(a.ID, 10),
+		Publisher:    models.NewsPublisher{Name: a.Source},
+		Title:        a.Headline,
+		PublishedUTC: time.Unix(a.Datetime, 0).UTC().Format(time.RFC3339),
+		ArticleURL:   a.URL,
+		Tickers:      []string{},
+		ImageURL:     a.Image,
+		Description:  a.Summary,
+	}
+	for _, ticker := range strings.Split(a.Related, ",") {
+		if ticker = strings.TrimSpace(ticker); ticker != "" {
+			article.Tickers = append(article.Tickers, ticker)
+		}
+	}
+	return article
+}
+
+// GetNews lists the last 30 days of company news, which is as far back as the free
+// tier goes. Finnhub returns it in one response, so it is paged locally by offset.
+func (c *FinnhubClient) GetNews(ctx context.Context, params models.NewsParams) (*models.NewsPage, error) {
+	articles, err := coalesce(ctx, c.inflight, cacheKey(methodNews, params.Ticker), func(ctx context.Context) ([]finnhubArticle, error) {
+		return c.fetchNews(ctx, params.Ticker)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	limit := params.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	limit = min(limit, 1000)
+	offset := 0
+	if params.Cursor != "" {
+		parsed, err := strconv.Atoi(params.Cursor)
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("invalid cursor %q", params.Cursor)
+		}
+		offset = parsed
+	}
+
+	page := &models.NewsPage{Results: []models.NewsArticle{}}
+	for _, article := range articles[min(offset, len(articles)):min(offset+limit, len(articles))] {
+		page.Results = append(page.Results, article.toArticle())
+	}
+	if offset+limit < len(articles) {
+		page.NextCursor = strconv.Itoa(offset + limit)
+	}
+	return page, nil
+}
+
+// fetchNews performs the HTTP request behind GetNews, returning articles newest first.
+func (c *FinnhubClient) fetchNews(ctx context.Context, ticker string) ([]finnhubArticle, error) {
+	log.Printf("GetNews called for ticker: %s", ticker)
+
+	now := time.Now().In(market.Location())
+	params := url.Values{
+		"symbol": {strings.ToUpper(ticker)},
+		"from":   {now.AddDate(0, 0, -30).Format("2006-01-02")},
+		"to":     {market.Today()},
+	}
+
+	var articles []finnhubArticle
+	if err := c.get(ctx, "/company-news", params, &articles); err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(articles, func(a, b finnhubArticle) int { return cmp.Compare(b.Datetime, a.Datetime) })
+	return articles, nil
+}
+
+// finnhubMarketStatus is the response from Finnhub's /stock/market-status endpoint
+type finnhubMarketStatus struct {
+	IsOpen  bool    `json:"isOpen"`
+	Session *string `json:"session"` // pre-market, regular, post-market, or null when closed
+	T       int64   `json:"t"`       // Unix seconds
+}
+
+// GetMarketStatus maps Finnhub's US market status onto Polygon's shape.
+func (c *FinnhubClient) GetMarketStatus(ctx context.Context) (*models.MarketStatusResponse, error) {
+	return coalesce(ctx, c.inflight, cacheKey(methodMarketStatus), func(ctx context.Context) (*models.MarketStatusResponse, error) {
+		var status finnhubMarketStatus
+		if err := c.get(ctx, "/stock/market-status", url.Values{"exchange": {"US"}}, &status); err != nil {
+			return nil, err
+		}
+
+		resp := &models.MarketStatusResponse{Market: "closed", ServerTime: time.Unix(status.T, 0).In(market.Location()).Format(time.RFC3339)}
+		session := ""
+		if status.Session != nil {
+			session = *status.Session
+		}
+		switch {
+		case status.IsOpen && session == "regular":
+			resp.Market = "open"
+		case session == "pre-market":
+			resp.Market, resp.EarlyHours = "extended-hours", true
+		case session == "post-market":
+			resp.Market, resp.AfterHours = "extended-hours", true
+		}
+		return resp, nil
+	})
+}
+
+// finnhubHoliday is an entry from Finnhub's /stock/market-holiday endpoint
+type finnhubHoliday struct {
+	EventName   string `json:"eventName"`
+	AtDate      string `json:"atDate"`      // YYYY-MM-DD
+	TradingHour string `json:"tradingHour"` // e.g. "09:30-13:00" on early closes; empty when closed
+}
+
+// GetMarketHolidays lists Finnhub's upcoming US market holidays, soonest first.
+func (c *FinnhubClient) GetMarketHolidays(ctx context.Context) (*models.MarketHolidaysResponse, error) {
+	return coalesce(ctx, c.inflight, cacheKey(methodMarketHolidays), func(ctx context.Context) (*models.MarketHolidaysResponse, error) {
+		var resp struct {
+			Data []finnhubHoliday `json:"data"`
+		}
+		if err := c.get(ctx, "/stock/market-holiday", url.Values{"exchange": {"US"}}, &resp); err != nil {
+			return nil, err
+		}
+
+		today := market.Today()
+		holidays := []models.MarketHoliday{}
+		for _, h := range resp.Data {
+			if h.AtDate < today {
+				continue
+			}
+			holiday := models.MarketHoliday{Exchange: "NYSE", Name: h.EventName, Date: h.AtDate, Status: "closed"}
+			if opens, closes, ok := strings.Cut(h.TradingHour, "-"); ok {
+				holiday.Status = "early-close"
+				holiday.Open = finnhubTradingTime(h.AtDate, opens)
+				holiday.Close = finnhubTradingTime(h.AtDate, closes)
+			}
+			holidays = append(holidays, holiday)
+		}
+		slices.SortFunc(holidays, func(a, b models.MarketHoliday) int { return strings.Compare(a.Date, b.Date) })
+		return &models.MarketHolidaysResponse{Results: holidays}, nil
+	})
+}
+
+// finnhubTradingTime turns a date and an HH:MM exchange time into RFC 3339
+func finnhubTradingTime(date, clock string) string {
+	t, err := time.ParseInLocation("2006-01-02 15:04", date+" "+strings.TrimSpace(clock), market.Location())
+	if err != nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
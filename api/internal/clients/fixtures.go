@@ -0,0 +1,487 @@
+package clients
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cole-zoom/dUW-app/api/internal/market"
+	"github.com/cole-zoom/dUW-app/api/internal/models"
+)
+
+// FixtureClient implements APIClient from a directory of JSON/CSV files so the
+// server can run without network access or an API key. The layout is:
+//
+//	tickers.json | tickers.csv            ticker listing (csv columns: ticker,name,market,locale,primary_exchange,type,currency_name)
+//	aggregates/{TICKER}.json | .csv       daily bars (csv columns: date,open,high,low,close,volume)
+//	aggregates/{TICKER}.{N}{timespan}.json  bars for other timespans, e.g. AAPL.5minute.json
+//	details/{TICKER}.json                 ticker details
+//	previous/{TICKER}.json                previous close; derived from daily bars when missing
+//	grouped/{YYYY-MM-DD}.json             grouped daily bars; derived from daily bars when missing
+//	dividends/{TICKER}.json               dividend history; none when missing
+//	splits/{TICKER}.json                  split history; none when missing
+//	news/{TICKER}.json                    array of news articles; none when missing
+//	financials/{TICKER}.{timeframe}.json  financial statements, e.g. AAPL.quarterly.json; none when missing
+//	market/status.json                    market status; derived from the trading calendar when missing
+//	market/holidays.json                  upcoming holidays; derived from the trading calendar when missing
+//
+// A RecordingClient writes files in the same layout.
+type FixtureClient struct {
+	dir string
+}
+
+func NewFixtureClient(dir string) *FixtureClient {
+	return &FixtureClient{dir: dir}
+}
+
+func (c *FixtureClient) GetSuggestedStocks(ctx context.Context, query string) ([]models.DisplayStock, error) {
+	page, err := c.ListTickersPage(ctx, models.TickerListParams{Search: query, Market: "stocks"})
+	if err != nil {
+		return nil, err
+	}
+	return models.ToDisplayStocks(page.Results), nil
+}
+
+// ListTickersPage filters the fixture listing like Polygon does: search matches a
+// ticker prefix or part of the name. Cursors are result offsets.
+func (c *FixtureClient) ListTickersPage(ctx context.Context, params models.TickerListParams) (*models.TickerPage, error) {
+	tickers, err := loadFixtureTickers(c.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	search := strings.ToLower(params.Search)
+	var matches []models.PolygonTickerResponse
+	for _, t := range tickers {
+		if params.Market != "" && t.Market != params.Market {
+			continue
+		}
+		if params.Type != "" && t.Type != params.Type {
+			continue
+		}
+		if search != "" && !strings.HasPrefix(strings.ToLower(t.Ticker), search) && !strings.Contains(strings.ToLower(t.Name), search) {
+			continue
+		}
+		matches = append(matches, t)
+	}
+
+	limit := params.Limit
+	if limit <= 0 || limit > 1000 {
+		limit = 1000
+	}
+	offset := 0
+	if params.Cursor != "" {
+		parsed, err := strconv.Atoi(params.Cursor)
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("invalid cursor %q", params.Cursor)
+		}
+		offset = parsed
+	}
+
+	page := &models.TickerPage{Results: matches[min(offset, len(matches)):min(offset+limit, len(matches))]}
+	if offset+limit < len(matches) {
+		page.NextCursor = strconv.Itoa(offset + limit)
+	}
+	return page, nil
+}
+
+// GetAggregates returns the fixture bars for a ticker that fall within [from, to]
+func (c *FixtureClient) GetAggregates(ctx context.Context, ticker, multiplier, timespan, from, to string) (*models.AggregatesResponse, error) {
+	bars, err := loadFixtureBars(c.dir, ticker, multiplier, timespan)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []models.AggregateBar{}
+	for _, bar := range bars {
+		if date := barDate(bar); date >= from && date <= to {
+			results = append(results, bar)
+		}
+	}
+
+	return &models.AggregatesResponse{
+		Ticker:       strings.ToUpper(ticker),
+		QueryCount:   len(results),
+		ResultsCount: len(results),
+		Adjusted:     true,
+		Results:      results,
+		Status:       "OK",
+	}, nil
+}
+
+func (c *FixtureClient) GetTickerDetails(ctx context.Context, ticker string) (*models.TickerDetails, error) {
+	var details models.TickerDetails
+	found, err := readFixtureJSON(filepath.Join(c.dir, "details", fixtureName(ticker)+".json"), &details)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, &TickerNotFoundError{Ticker: ticker}
+	}
+	return &details, nil
+}
+
+// GetPreviousClose serves previous/{TICKER}.json, or else the last daily bar before today
+func (c *FixtureClient) GetPreviousClose(ctx context.Context, ticker string) (*models.PreviousCloseResponse, error) {
+	var response models.PreviousCloseResponse
+	found, err := readFixtureJSON(filepath.Join(c.dir, "previous", fixtureName(ticker)+".json"), &response)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return &response, nil
+	}
+
+	bars, err := loadFixtureBars(c.dir, ticker, "1", "day")
+	if err != nil {
+		return nil, err
+	}
+	response = models.PreviousCloseResponse{
+		Ticker:   strings.ToUpper(ticker),
+		Adjusted: true,
+		Results:  []models.AggregateBar{},
+		Status:   "OK",
+	}
+	today := market.Today()
+	for i := len(bars) - 1; i >= 0; i-- {
+		if barDate(bars[i]) < today {
+			bar := bars[i]
+			bar.Ticker = strings.ToUpper(ticker)
+			response.Results = append(response.Results, bar)
+			response.QueryCount, response.ResultsCount = 1, 1
+			break
+		}
+	}
+	return &response, nil
+}
+
+// GetGroupedDaily serves grouped/{date}.json, or else assembles that day's bar from every daily fixture
+func (c *FixtureClient) GetGroupedDaily(ctx context.Context, date string) (*models.GroupedDailyResponse, error) {
+	var response models.GroupedDailyResponse
+	found, err := readFixtureJSON(filepath.Join(c.dir, "grouped", date+".json"), &response)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return &response, nil
+	}
+
+	response = models.GroupedDailyResponse{Adjusted: true, Results: []models.AggregateBar{}, Status: "OK"}
+	entries, err := os.ReadDir(filepath.Join(c.dir, "aggregates"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read aggregates fixtures: %w", err)
+	}
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		ticker := strings.TrimSuffix(entry.Name(), ext)
+		if ext != ".json" && ext != ".csv" || isTimespanFixture(ticker) || seen[ticker] {
+			continue
+		}
+		seen[ticker] = true
+
+		bars, err := loadFixtureBars(c.dir, ticker, "1", "day")
+		if err != nil {
+			return nil, err
+		}
+		for _, bar := range bars {
+			if barDate(bar) == date {
+				bar.Ticker = ticker
+				response.Results = append(response.Results, bar)
+				break
+			}
+		}
+	}
+	response.QueryCount, response.ResultsCount = len(response.Results), len(response.Results)
+	return &response, nil
+}
+
+// GetDividends serves dividends/{TICKER}.json; a ticker without one has never paid a dividend
+func (c *FixtureClient) GetDividends(ctx context.Context, ticker string) (*models.DividendsResponse, error) {
+	response := models.DividendsResponse{Ticker: strings.ToUpper(ticker), Results: []models.Dividend{}, Status: "OK"}
+	if _, err := readFixtureJSON(filepath.Join(c.dir, "dividends", fixtureName(ticker)+".json"), &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetSplits serves splits/{TICKER}.json; a ticker without one has never split
+func (c *FixtureClient) GetSplits(ctx context.Context, ticker string) (*models.SplitsResponse, error) {
+	response := models.SplitsResponse{Ticker: strings.ToUpper(ticker), Results: []models.Split{}, Status: "OK"}
+	if _, err := readFixtureJSON(filepath.Join(c.dir, "splits", fixtureName(ticker)+".json"), &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetFinancials serves financials/{TICKER}.{timeframe}.json; a ticker without one has filed nothing
+func (c *FixtureClient) GetFinancials(ctx context.Context, ticker, timeframe string) (*models.FinancialsResponse, error) {
+	response := models.FinancialsResponse{Ticker: strings.ToUpper(ticker), Timeframe: timeframe, Results: []models.FinancialReport{}, Status: "OK"}
+	if _, err := readFixtureJSON(financialsFixturePath(c.dir, ticker, timeframe), &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func financialsFixturePath(dir, ticker, timeframe string) string {
+	return filepath.Join(dir, "financials", fixtureName(ticker)+"."+strings.ToLower(fixtureName(timeframe))+".json")
+}
+
+// GetMarketStatus serves market/status.json, or the status the trading calendar gives for now
+func (c *FixtureClient) GetMarketStatus(ctx context.Context) (*models.MarketStatusResponse, error) {
+	var response models.MarketStatusResponse
+	found, err := readFixtureJSON(filepath.Join(c.dir, "market", "status.json"), &response)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return &response, nil
+	}
+
+	now := time.Now()
+	response = models.MarketStatusResponse{Market: "closed", ServerTime: now.In(market.Location()).Format(time.RFC3339)}
+	switch market.PhaseAt(now) {
+	case market.PhaseOpen:
+		response.Market = "open"
+	case market.PhasePreMarket:
+		response.Market, response.EarlyHours = "extended-hours", true
+	case market.PhaseAfterHours:
+		response.Market, response.AfterHours = "extended-hours", true
+	}
+	return &response, nil
+}
+
+// GetMarketHolidays serves market/holidays.json, or the next year of the trading calendar's holidays
+func (c *FixtureClient) GetMarketHolidays(ctx context.Context) (*models.MarketHolidaysResponse, error) {
+	var response models.MarketHolidaysResponse
+	found, err := readFixtureJSON(filepath.Join(c.dir, "market", "holidays.json"), &response)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return &response, nil
+	}
+
+	now := time.Now()
+	response.Results = []models.MarketHoliday{}
+	for _, h := range market.Holidays(now, now.AddDate(1, 0, 0)) {
+		holiday := models.MarketHoliday{Exchange: "NYSE", Name: h.Name, Date: h.Date, Status: "closed"}
+		if !h.Closed() {
+			session, _ := market.SessionOn(h.Close)
+			holiday.Status = "early-close"
+			holiday.Open = session.Open.Format(time.RFC3339)
+			holiday.Close = h.Close.Format(time.RFC3339)
+		}
+		response.Results = append(response.Results, holiday)
+	}
+	return &response, nil
+}
+
+// GetNews serves news/{TICKER}.json newest first. Cursors are result offsets.
+func (c *FixtureClient) GetNews(ctx context.Context, params models.NewsParams) (*models.NewsPage, error) {
+	articles := []models.NewsArticle{}
+	if _, err := readFixtureJSON(newsFixturePath(c.dir, params.Ticker), &articles); err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(articles, func(a, b models.NewsArticle) int { return strings.Compare(b.PublishedUTC, a.PublishedUTC) })
+
+	limit := params.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	limit = min(limit, 1000)
+	offset := 0
+	if params.Cursor != "" {
+		parsed, err := strconv.Atoi(params.Cursor)
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("invalid cursor %q", params.Cursor)
+		}
+		offset = parsed
+	}
+
+	page := &models.NewsPage{Results: articles[min(offset, len(articles)):min(offset+limit, len(articles))]}
+	if offset+limit < len(articles) {
+		page.NextCursor = strconv.Itoa(offset + limit)
+	}
+	return page, nil
+}
+
+func newsFixturePath(dir, ticker string) string {
+	return filepath.Join(dir, "news", fixtureName(ticker)+".json")
+}
+
+// fixtureName makes a ticker safe to use as a file name
+func fixtureName(ticker string) string {
+	return strings.NewReplacer("/", "_", "\\", "_", "..", "_").Replace(strings.ToUpper(ticker))
+}
+
+// aggregatesFixtureBase is the aggregates file path without its extension
+func aggregatesFixtureBase(dir, ticker, multiplier, timespan string) string {
+	name := fixtureName(ticker)
+	if multiplier != "1" || timespan != "day" {
+		name += "." + multiplier + timespan
+	}
+	return filepath.Join(dir, "aggregates", name)
+}
+
+// isTimespanFixture reports whether a file name ends in a timespan suffix like
+// ".5minute", as opposed to a share class like "BRK.B"
+func isTimespanFixture(name string) bool {
+	i := strings.LastIndex(name, ".")
+	if i < 0 || i+1 >= len(name) {
+		return false
+	}
+	suffix := name[i+1:]
+	return suffix[0] >= '0' && suffix[0] <= '9'
+}
+
+// barDate is the market-time trading day a bar starts on
+func barDate(bar models.AggregateBar) string {
+	return time.UnixMilli(int64(bar.Timestamp)).In(market.Location()).Format("2006-01-02")
+}
+
+// readFixtureJSON decodes a fixture file, reporting false if it doesn't exist
+func readFixtureJSON(path string, out any) (bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read fixture %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return false, fmt.Errorf("failed to decode fixture %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// readFixtureCSV returns the data rows of a CSV fixture keyed by its header, or nil if it doesn't exist
+func readFixtureCSV(path string) ([]map[string]string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture %s: %w", path, err)
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			if i < len(record) {
+				row[strings.TrimSpace(column)] = strings.TrimSpace(record[i])
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// loadFixtureTickers reads tickers.json, falling back to tickers.csv, sorted by ticker
+func loadFixtureTickers(dir string) ([]models.PolygonTickerResponse, error) {
+	var tickers []models.PolygonTickerResponse
+	found, err := readFixtureJSON(filepath.Join(dir, "tickers.json"), &tickers)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		rows, err := readFixtureCSV(filepath.Join(dir, "tickers.csv"))
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			tickers = append(tickers, models.PolygonTickerResponse{
+				Ticker:          row["ticker"],
+				Name:            row["name"],
+				Market:          row["market"],
+				Locale:          row["locale"],
+				PrimaryExchange: row["primary_exchange"],
+				Type:            row["type"],
+				Active:          true,
+				CurrencyName:    row["currency_name"],
+			})
+		}
+	}
+
+	slices.SortFunc(tickers, func(a, b models.PolygonTickerResponse) int { return strings.Compare(a.Ticker, b.Ticker) })
+	return tickers, nil
+}
+
+// loadFixtureBars reads a ticker's bars from JSON, falling back to CSV, sorted by time.
+// A ticker without fixtures has no bars.
+func loadFixtureBars(dir, ticker, multiplier, timespan string) ([]models.AggregateBar, error) {
+	base := aggregatesFixtureBase(dir, ticker, multiplier, timespan)
+
+	var response models.AggregatesResponse
+	found, err := readFixtureJSON(base+".json", &response)
+	if err != nil {
+		return nil, err
+	}
+	bars := response.Results
+	if !found {
+		rows, err := readFixtureCSV(base + ".csv")
+		if err != nil {
+			return nil, err
+		}
+		for i, row := range rows {
+			bar, err := csvBar(row)
+			if err != nil {
+				return nil, fmt.Errorf("%s.csv row %d: %w", base, i+2, err)
+			}
+			bars = append(bars, bar)
+		}
+	}
+
+	slices.SortFunc(bars, func(a, b models.AggregateBar) int { return cmp.Compare(a.Timestamp, b.Timestamp) })
+	return bars, nil
+}
+
+// csvBar parses a date,open,high,low,close,volume row; the bar starts at midnight market time
+func csvBar(row map[string]string) (models.AggregateBar, error) {
+	day, err := time.ParseInLocation("2006-01-02", row["date"], market.Location())
+	if err != nil {
+		return models.AggregateBar{}, fmt.Errorf("invalid date %q", row["date"])
+	}
+
+	values := make(map[string]float64, 5)
+	for _, column := range []string{"open", "high", "low", "close", "volume"} {
+		if row[column] == "" {
+			continue // Volume in particular is often omitted
+		}
+		v, err := strconv.ParseFloat(row[column], 64)
+		if err != nil {
+			return models.AggregateBar{}, fmt.Errorf("invalid %s %q", column, row[column])
+		}
+		values[column] = v
+	}
+
+	return models.AggregateBar{
+		Open:      values["open"],
+		High:      values["high"],
+		Low:       values["low"],
+		Close:     values["close"],
+		Volume:    values["volume"],
+		Timestamp: models.FlexibleInt64(day.UnixMilli()),
+	}, nil
+}
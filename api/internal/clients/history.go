@@ -0,0 +1,276 @@
+package clients
+
+import (
+	"context"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cole-zoom/dUW-app/api/internal/history"
+	"github.com/cole-zoom/dUW-app/api/internal/market"
+	"github.com/cole-zoom/dUW-app/api/internal/models"
+)
+
+// splitCheckInterval is how often stored history is checked for splits that
+// would change its adjusted prices
+const splitCheckInterval = 24 * time.Hour
+
+// PriceHistoryClient serves aggregates from stored price history, fetching only
+// the dates it doesn't have yet from the next client and saving them. Only closed
+// days are stored; today's bars always come from the provider. Bar sizes that
+// don't divide a day evenly (weeks, months, multi-day bars) pass straight through,
+// since a range split into pieces would leave partial bars at the seams.
+// Every other method passes through unchanged.
+type PriceHistoryClient struct {
+	APIClient
+	store history.Store
+}
+
+func NewPriceHistoryClient(next APIClient, store history.Store) *PriceHistoryClient {
+	return &PriceHistoryClient{APIClient: next, store: store}
+}
+
+func (c *PriceHistoryClient) GetAggregates(ctx context.Context, ticker, multiplier, timespan, from, to string) (*models.AggregatesResponse, error) {
+	series, ok := historySeries(ticker, multiplier, timespan)
+	if !ok {
+		return c.APIClient.GetAggregates(ctx, ticker, multiplier, timespan, from, to)
+	}
+	start, err1 := time.ParseInLocation("2006-01-02", from, market.Location())
+	end, err2 := time.ParseInLocation("2006-01-02", to, market.Location())
+	if err1 != nil || err2 != nil {
+		return c.APIClient.GetAggregates(ctx, ticker, multiplier, timespan, from, to)
+	}
+
+	// Only days before today are closed for good
+	today, _ := time.ParseInLocation("2006-01-02", market.Today(), market.Location())
+	closedEnd := minTime(end, today.AddDate(0, 0, -1))
+	if start.After(closedEnd) {
+		return c.APIClient.GetAggregates(ctx, ticker, multiplier, timespan, from, to)
+	}
+
+	response := &models.AggregatesResponse{Ticker: series.Ticker, Adjusted: true, Status: "OK", Source: "history"}
+	bars, err := c.closedBars(ctx, series, start, closedEnd, response)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		// A broken store shouldn't break charts
+		log.Printf("PriceHistoryClient - Falling back to the provider for %s: %v", series.Ticker, err)
+		return c.APIClient.GetAggregates(ctx, ticker, multiplier, timespan, from, to)
+	}
+	response.Results = bars
+
+	if end.After(closedEnd) {
+		live, err := c.APIClient.GetAggregates(ctx, ticker, multiplier, timespan, today.Format("2006-01-02"), to)
+		if err != nil {
+			return nil, err
+		}
+		response.Results = append(response.Results, live.Results...)
+		response.Source = live.Source
+	}
+
+	response.QueryCount, response.ResultsCount = len(response.Results), len(response.Results)
+	return response, nil
+}
+
+// closedBars returns the series' bars for the closed days [start, end], fetching and
+// saving the dates not stored yet. The provider's name is recorded on response when
+// any are fetched.
+func (c *PriceHistoryClient) closedBars(ctx context.Context, series history.Series, start, end time.Time, response *models.AggregatesResponse) ([]models.AggregateBar, error) {
+	ranges, err := c.store.Ranges(ctx, series)
+	if err != nil {
+		return nil, err
+	}
+	if ranges, err = c.checkSplits(ctx, series.Ticker, ranges); err != nil {
+		return nil, err
+	}
+
+	settled := settledBefore(time.Now())
+	for _, gap := range missingRanges(ranges, start, end) {
+		if !hasTradingDay(gap[0], gap[1]) {
+			if err := c.store.Save(ctx, series, gap[0].Format("2006-01-02"), gap[1].Format("2006-01-02"), nil); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// A response can stop short of the end of the gap, cut off by the provider's result
+		// limit, so only the dates it reached are saved and the rest is asked for again.
+		for from := gap[0]; !from.After(gap[1]); {
+			fetched, err := c.APIClient.GetAggregates(ctx, series.Ticker, strconv.Itoa(series.Multiplier), series.Timespan, from.Format("2006-01-02"), gap[1].Format("2006-01-02"))
+			if err != nil {
+				return nil, err
+			}
+			response.Source = fetched.Source
+
+			coveredFrom, coveredTo, ok := coveredSpan(fetched.Results, from, gap[1])
+			if !ok {
+				// The provider has nothing more for these dates: they're before the listing,
+				// beyond the plan's history, or after a delisting. Recording that spares an
+				// upstream call on every later request, but recent bars may simply not be
+				// published yet, so those dates are tried again next time.
+				if !gap[1].Before(settled) {
+					break
+				}
+				coveredFrom, coveredTo = from, gap[1]
+			}
+			bars := barsBetween(fetched.Results, coveredFrom, coveredTo)
+			if err := c.store.Save(ctx, series, coveredFrom.Format("2006-01-02"), coveredTo.Format("2006-01-02"), bars); err != nil {
+				return nil, err
+			}
+			from = coveredTo.AddDate(0, 0, 1)
+		}
+	}
+
+	return c.store.Bars(ctx, series, start, end.AddDate(0, 0, 1))
+}
+
+// checkSplits drops a ticker's stored history when it has split since the history
+// was last verified, since the provider's split-adjusted prices will have changed.
+// Lookups that fail leave the history in place; it is checked again next time.
+func (c *PriceHistoryClient) checkSplits(ctx context.Context, ticker string, ranges []history.Range) ([]history.Range, error) {
+	if len(ranges) == 0 {
+		return ranges, nil
+	}
+	oldest := slices.MinFunc(ranges, func(a, b history.Range) int { return a.VerifiedAt.Compare(b.VerifiedAt) }).VerifiedAt
+	if time.Since(oldest) < splitCheckInterval {
+		return ranges, nil
+	}
+
+	splits, err := c.APIClient.GetSplits(ctx, ticker)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		log.Printf("PriceHistoryClient - Failed to check %s for splits: %v", ticker, err)
+		return ranges, nil
+	}
+
+	// A split on the day of the last check may have executed after it, so that day counts too
+	verified := oldest.In(market.Location()).Format("2006-01-02")
+	for _, split := range splits.Results {
+		if split.ExecutionDate >= verified && split.ExecutionDate <= market.Today() {
+			log.Printf("PriceHistoryClient - %s split on %s; discarding its stored history", ticker, split.ExecutionDate)
+			return nil, c.store.Forget(ctx, ticker)
+		}
+	}
+	return ranges, c.store.Verify(ctx, ticker, time.Now())
+}
+
+// historySeries returns the stored series for a request, or false when the bar size isn't stored
+func historySeries(ticker, multiplier, timespan string) (history.Series, bool) {
+	n, err := strconv.Atoi(multiplier)
+	if err != nil || n < 1 {
+		return history.Series{}, false
+	}
+	switch {
+	case timespan == "minute" && 60%n == 0,
+		timespan == "hour" && n == 1,
+		timespan == "day" && n == 1:
+		return history.Series{Ticker: strings.ToUpper(ticker), Timespan: timespan, Multiplier: n}, true
+	default:
+		return history.Series{}, false
+	}
+}
+
+// missingRanges returns the spans of dates in [start, end] no stored range covers
+func missingRanges(ranges []history.Range, start, end time.Time) [][2]time.Time {
+	var gaps [][2]time.Time
+	next := start // First date not yet known to be covered
+	for _, r := range ranges {
+		from, err1 := time.ParseInLocation("2006-01-02", r.From, market.Location())
+		to, err2 := time.ParseInLocation("2006-01-02", r.To, market.Location())
+		if err1 != nil || err2 != nil || to.Before(next) {
+			continue
+		}
+		if from.After(end) {
+			break
+		}
+		if from.After(next) {
+			gaps = append(gaps, [2]time.Time{next, from.AddDate(0, 0, -1)})
+		}
+		next = to.AddDate(0, 0, 1)
+	}
+	if !next.After(end) {
+		gaps = append(gaps, [2]time.Time{next, end})
+	}
+	return gaps
+}
+
+// coveredSpan returns the dates in [from, to] that a response's bars are known to
+// cover completely. Responses run oldest first, so any dates before the first bar
+// had none; after the last bar, non-trading days are covered too. When trading days
+// follow the last bar the response was cut off, and its last day may be too, so that
+// day is left out. Returns false when nothing is known to be covered.
+func coveredSpan(bars []models.AggregateBar, from, to time.Time) (time.Time, time.Time, bool) {
+	if len(bars) == 0 {
+		return time.Time{}, time.Time{}, false
+	}
+	last := barDay(bars[0])
+	for _, bar := range bars[1:] {
+		last = maxTime(last, barDay(bar))
+	}
+
+	start := from
+	end := to
+	if hasTradingDay(last.AddDate(0, 0, 1), to) {
+		end = last.AddDate(0, 0, -1)
+	}
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, false
+	}
+	return start, end, true
+}
+
+// settledBefore returns the date of the latest session that closed before t's date.
+// Bars for days before it have had at least a full day to be published.
+func settledBefore(t time.Time) time.Time {
+	local := t.In(market.Location())
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, market.Location())
+	latest := market.LastSessions(today.Add(-time.Nanosecond), 1)[0]
+	day, _ := time.ParseInLocation("2006-01-02", latest.Date(), market.Location())
+	return day
+}
+
+// barsBetween returns the bars starting on dates in [from, to]
+func barsBetween(bars []models.AggregateBar, from, to time.Time) []models.AggregateBar {
+	var between []models.AggregateBar
+	for _, bar := range bars {
+		if date := barDay(bar); !date.Before(from) && !date.After(to) {
+			between = append(between, bar)
+		}
+	}
+	return between
+}
+
+// barDay returns midnight market time on the day a bar starts
+func barDay(bar models.AggregateBar) time.Time {
+	day, _ := time.ParseInLocation("2006-01-02", barDate(bar), market.Location())
+	return day
+}
+
+// hasTradingDay reports whether any date in [from, to] has a trading session
+func hasTradingDay(from, to time.Time) bool {
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if market.IsTradingDay(day) {
+			return true
+		}
+	}
+	return false
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
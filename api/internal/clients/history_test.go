@@ -0,0 +1,193 @@
+package clients
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cole-zoom/dUW-app/api/internal/history"
+	"github.com/cole-zoom/dUW-app/api/internal/market"
+	"github.com/cole-zoom/dUW-app/api/internal/models"
+)
+
+// memoryHistory is a history.Store kept in memory
+type memoryHistory struct {
+	ranges    []history.Range
+	bars      map[int64]models.AggregateBar
+	forgotten bool
+}
+
+func newMemoryHistory() *memoryHistory {
+	return &memoryHistory{bars: map[int64]models.AggregateBar{}}
+}
+
+func (m *memoryHistory) Ranges(ctx context.Context, series history.Series) ([]history.Range, error) {
+	ranges := slices.Clone(m.ranges)
+	slices.SortFunc(ranges, func(a, b history.Range) int { return strings.Compare(a.From, b.From) })
+	return ranges, nil
+}
+
+func (m *memoryHistory) Bars(ctx context.Context, series history.Series, start, end time.Time) ([]models.AggregateBar, error) {
+	var bars []models.AggregateBar
+	for ts, bar := range m.bars {
+		if ts >= start.UnixMilli() && ts < end.UnixMilli() {
+			bars = append(bars, bar)
+		}
+	}
+	slices.SortFunc(bars, func(a, b models.AggregateBar) int { return cmp.Compare(a.Timestamp, b.Timestamp) })
+	return bars, nil
+}
+
+func (m *memoryHistory) Save(ctx context.Context, series history.Series, from, to string, bars []models.AggregateBar) error {
+	m.ranges = append(m.ranges, history.Range{From: from, To: to, VerifiedAt: time.Now()})
+	for _, bar := range bars {
+		m.bars[int64(bar.Timestamp)] = bar
+	}
+	return nil
+}
+
+func (m *memoryHistory) Verify(ctx context.Context, ticker string, at time.Time) error { return nil }
+
+func (m *memoryHistory) Forget(ctx context.Context, ticker string) error {
+	m.ranges, m.bars, m.forgotten = nil, map[int64]models.AggregateBar{}, true
+	return nil
+}
+
+// barProvider serves one daily bar per trading day, starting no earlier than earliest
+// and returning at most limit bars per request (all of them when limit is zero)
+type barProvider struct {
+	APIClient
+	earliest time.Time
+	limit    int
+	splits   []models.Split
+	requests [][2]string
+}
+
+func (p *barProvider) GetSplits(ctx context.Context, ticker string) (*models.SplitsResponse, error) {
+	return &models.SplitsResponse{Results: p.splits}, nil
+}
+
+func (p *barProvider) GetAggregates(ctx context.Context, ticker, multiplier, timespan, from, to string) (*models.AggregatesResponse, error) {
+	p.requests = append(p.requests, [2]string{from, to})
+	start, _ := time.ParseInLocation("2006-01-02", from, market.Location())
+	end, _ := time.ParseInLocation("2006-01-02", to, market.Location())
+
+	var bars []models.AggregateBar
+	for day := maxTime(start, p.earliest); !day.After(end); day = day.AddDate(0, 0, 1) {
+		if p.limit > 0 && len(bars) == p.limit {
+			break
+		}
+		if market.IsTradingDay(day) {
+			bars = append(bars, models.AggregateBar{Timestamp: models.FlexibleInt64(day.UnixMilli()), Close: 100})
+		}
+	}
+	return &models.AggregatesResponse{Ticker: ticker, Results: bars, ResultsCount: len(bars), Source: "test"}, nil
+}
+
+func marketDate(t *testing.T, date string) time.Time {
+	t.Helper()
+	day, err := time.ParseInLocation("2006-01-02", date, market.Location())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return day
+}
+
+func TestPriceHistoryFetchesPastTruncatedResponses(t *testing.T) {
+	store := newMemoryHistory()
+	provider := &barProvider{limit: 3}
+	client := NewPriceHistoryClient(provider, store)
+
+	// 2024-03-04 to 2024-03-15 is two full trading weeks
+	resp, err := client.GetAggregates(context.Background(), "AAPL", "1", "day", "2024-03-04", "2024-03-15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.ResultsCount != 10 {
+		t.Errorf("got %d bars, want 10", resp.ResultsCount)
+	}
+
+	// Each cut-off response is followed by another starting on its last, possibly partial, day
+	want := [][2]string{
+		{"2024-03-04", "2024-03-15"},
+		{"2024-03-06", "2024-03-15"},
+		{"2024-03-08", "2024-03-15"},
+		{"2024-03-12", "2024-03-15"},
+		{"2024-03-14", "2024-03-15"},
+	}
+	if !slices.Equal(provider.requests, want) {
+		t.Errorf("requests = %v, want %v", provider.requests, want)
+	}
+
+	ranges, _ := store.Ranges(context.Background(), history.Series{})
+	if gaps := missingRanges(ranges, marketDate(t, "2024-03-04"), marketDate(t, "2024-03-15")); len(gaps) != 0 {
+		t.Errorf("gaps left after a complete fetch: %v", gaps)
+	}
+}
+
+func TestPriceHistoryRemembersDatesWithoutBars(t *testing.T) {
+	// The plan's history only goes back to 2024-03-11
+	provider := &barProvider{earliest: marketDate(t, "2024-03-11")}
+
+	tests := []struct {
+		name     string
+		from, to string
+		bars     int
+	}{
+		{"before the first bar", "2024-03-04", "2024-03-15", 5},
+		{"with no bars at all", "2024-03-04", "2024-03-08", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemoryHistory()
+			client := NewPriceHistoryClient(provider, store)
+
+			provider.requests = nil
+			resp, err := client.GetAggregates(context.Background(), "AAPL", "1", "day", tt.from, tt.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.ResultsCount != tt.bars {
+				t.Errorf("got %d bars, want %d", resp.ResultsCount, tt.bars)
+			}
+			if want := [][2]string{{tt.from, tt.to}}; !slices.Equal(provider.requests, want) {
+				t.Errorf("requests = %v, want %v", provider.requests, want)
+			}
+
+			ranges, _ := store.Ranges(context.Background(), history.Series{})
+			if gaps := missingRanges(ranges, marketDate(t, tt.from), marketDate(t, tt.to)); len(gaps) != 0 {
+				t.Fatalf("gaps = %v, want none", gaps)
+			}
+
+			// The provider isn't asked again for dates it had nothing for
+			provider.requests = nil
+			if _, err := client.GetAggregates(context.Background(), "AAPL", "1", "day", tt.from, tt.to); err != nil {
+				t.Fatal(err)
+			}
+			if len(provider.requests) != 0 {
+				t.Errorf("requests = %v, want none", provider.requests)
+			}
+		})
+	}
+}
+
+func TestPriceHistoryForgetsSplitOnVerificationDay(t *testing.T) {
+	verified := time.Date(2024, time.March, 5, 18, 0, 0, 0, market.Location())
+	store := newMemoryHistory()
+	store.ranges = []history.Range{{From: "2024-03-04", To: "2024-03-08", VerifiedAt: verified}}
+	provider := &barProvider{splits: []models.Split{{Ticker: "AAPL", ExecutionDate: "2024-03-05"}}}
+	client := NewPriceHistoryClient(provider, store)
+
+	if _, err := client.GetAggregates(context.Background(), "AAPL", "1", "day", "2024-03-04", "2024-03-08"); err != nil {
+		t.Fatal(err)
+	}
+	if !store.forgotten {
+		t.Error("history kept after a split on the day it was last verified")
+	}
+	if want := [][2]string{{"2024-03-04", "2024-03-08"}}; !slices.Equal(provider.requests, want) {
+		t.Errorf("requests = %v, want %v", provider.requests, want)
+	}
+}
@@ -0,0 +1,35 @@
+package clients
+
+import (
+	"context"
+	"iter"
+
+	"github.com/cole-zoom/dUW-app/api/internal/models"
+)
+
+// ListTickers iterates over every ticker matching params, following Polygon's
+// next_url cursor page by page. Each page goes through the client (and so its
+// rate limiter). maxPages bounds the number of upstream requests; 0 means no limit.
+// Iteration stops after yielding the first error.
+func ListTickers(ctx context.Context, client APIClient, params models.TickerListParams, maxPages int) iter.Seq2[models.PolygonTickerResponse, error] {
+	return func(yield func(models.PolygonTickerResponse, error) bool) {
+		for pages := 0; maxPages == 0 || pages < maxPages; pages++ {
+			page, err := client.ListTickersPage(ctx, params)
+			if err != nil {
+				yield(models.PolygonTickerResponse{}, err)
+				return
+			}
+
+			for _, ticker := range page.Results {
+				if !yield(ticker, nil) {
+					return
+				}
+			}
+
+			if page.NextCursor == "" {
+				return
+			}
+			params.Cursor = page.NextCursor
+		}
+	}
+}
@@ -2,115 +2,278 @@ package clients
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log"
+	"maps"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/cole-zoom/dUW-app/api/internal/models"
-	"golang.org/x/time/rate"
 )
 
-type PolygonClient struct {
-	httpClient  *http.Client
-	apiKey      string
-	rateLimiter *rate.Limiter
+// PolygonConfig describes the Polygon plan in use. The request limit applies to
+// each API key separately, so a pool of keys multiplies throughput.
+type PolygonConfig struct {
+	APIKeys           []string
+	RequestsPerMinute int
+	Burst             int
+	BaseURL           string
+	Timeout           time.Duration
 }
 
-func NewPolygonClient(apiKey string) *PolygonClient {
-	return &PolygonClient{
-		httpClient: &http.Client{Timeout: 10 * time.Second},
-		apiKey:     apiKey,
-		// Polygon.io free tier: 5 requests/minute = 1 every 12 seconds
+// DefaultPolygonConfig returns the free tier limits; zero fields in a config passed
+// to NewPolygonClient fall back to these
+func DefaultPolygonConfig() PolygonConfig {
+	return PolygonConfig{
+		// Polygon.io free tier: 5 requests/minute
 		// Burst of 5 allows initial requests to go through quickly
-		rateLimiter: rate.NewLimiter(rate.Every(12*time.Second), 5),
+		RequestsPerMinute: 5,
+		Burst:             5,
+		BaseURL:           "https://api.polygon.io",
+		Timeout:           10 * time.Second,
+	}
+}
+
+type PolygonClient struct {
+	httpClient *http.Client
+	baseURL    string
+	retry      RetryPolicy
+	inflight   *coalescer
+
+	mu   sync.Mutex
+	keys []*polygonKey
+	next int // Where the next key search starts, so ties rotate through the pool
+}
+
+// keyRevocationCooldown is how long a rejected API key sits out before it is tried
+// again, in case it was rejected by mistake or has since been reinstated
+const keyRevocationCooldown = 15 * time.Minute
+
+// polygonKey is one API key with its own rate limit
+type polygonKey struct {
+	value        string
+	scheduler    *Scheduler
+	revokedUntil time.Time // Rejected with 401; skipped until then
+}
+
+// revoked reports whether the key is sitting out after being rejected
+func (k *polygonKey) revoked(now time.Time) bool {
+	return now.Before(k.revokedUntil)
+}
+
+func NewPolygonClient(config PolygonConfig) *PolygonClient {
+	defaults := DefaultPolygonConfig()
+	if config.RequestsPerMinute <= 0 {
+		config.RequestsPerMinute = defaults.RequestsPerMinute
 	}
+	if config.Burst <= 0 {
+		config.Burst = defaults.Burst
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = defaults.BaseURL
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+
+	c := &PolygonClient{
+		httpClient: &http.Client{Timeout: config.Timeout},
+		baseURL:    strings.TrimRight(config.BaseURL, "/"),
+		retry:      DefaultRetryPolicy(),
+		inflight:   newCoalescer(),
+	}
+	every := time.Minute / time.Duration(config.RequestsPerMinute)
+	for _, key := range config.APIKeys {
+		c.keys = append(c.keys, &polygonKey{value: key, scheduler: NewScheduler(every, config.Burst)})
+	}
+	return c
 }
 
-// waitForRateLimit waits for the rate limiter before making an API call.
-// Returns an error if the context is cancelled while waiting.
-func (c *PolygonClient) waitForRateLimit(ctx context.Context) error {
-	if err := c.rateLimiter.Wait(ctx); err != nil {
-		return fmt.Errorf("rate limit wait failed: %w", err)
+// acquireKey picks the usable key with the shortest queue for this request's
+// priority and waits for its rate limiter. Returns an error if the context is
+// cancelled while waiting or every key is currently revoked.
+func (c *PolygonClient) acquireKey(ctx context.Context) (*polygonKey, error) {
+	c.mu.Lock()
+	now := time.Now()
+	var best *polygonKey
+	var bestWait time.Duration
+	for i := range c.keys {
+		key := c.keys[(c.next+i)%len(c.keys)]
+		if key.revoked(now) {
+			continue
+		}
+		if wait := key.scheduler.estimate(ctx); best == nil || wait < bestWait {
+			best, bestWait = key, wait
+		}
+	}
+	c.next++
+	c.mu.Unlock()
+
+	if best == nil {
+		return nil, fmt.Errorf("%w: every Polygon API key has been rejected", ErrUnauthorized)
+	}
+	if err := best.scheduler.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit wait failed: %w", err)
+	}
+	return best, nil
+}
+
+// revoke takes a rejected key out of rotation for keyRevocationCooldown and reports
+// whether any usable keys remain
+func (c *PolygonClient) revoke(key *polygonKey) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if !key.revoked(now) {
+		key.revokedUntil = now.Add(keyRevocationCooldown)
+		log.Printf("PolygonClient - API key %s was rejected, taking it out of rotation for %v", maskKey(key.value), keyRevocationCooldown)
+	}
+	for _, k := range c.keys {
+		if !k.revoked(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// RateLimitStats reports the request queue in front of each Polygon API key
+func (c *PolygonClient) RateLimitStats() []SchedulerStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	stats := make([]SchedulerStats, 0, len(c.keys))
+	for _, key := range c.keys {
+		keyStats := key.scheduler.Stats()
+		keyStats.Key = maskKey(key.value)
+		keyStats.Revoked = key.revoked(now)
+		stats = append(stats, keyStats)
+	}
+	return stats
+}
+
+// maskKey shortens an API key to its last four characters for logs and health output
+func maskKey(key string) string {
+	if len(key) <= 4 {
+		return "****"
+	}
+	return "…" + key[len(key)-4:]
+}
+
+// getJSON requests a Polygon endpoint, retrying transient failures, and decodes the JSON body into out.
+// Every attempt waits on the rate limiter of the key it uses. A key rejected with
+// 401 is revoked and the request is sent again with another key; a 403 means the
+// plan doesn't cover the request, which another key on the same plan won't fix.
+func (c *PolygonClient) getJSON(ctx context.Context, baseURL string, params url.Values, out any) error {
+	log.Printf("Making API request to: %s", baseURL) // Don't log API key
+
+	for {
+		var key *polygonKey
+		wait := func(ctx context.Context) error {
+			var err error
+			key, err = c.acquireKey(ctx)
+			return err
+		}
+		err := fetchJSON(ctx, c.httpClient, c.retry, wait, func(ctx context.Context) (*http.Request, error) {
+			query := maps.Clone(params)
+			query.Set("apiKey", key.value)
+			return http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"?"+query.Encode(), nil)
+		}, out)
+
+		if key != nil && errors.Is(err, ErrUnauthorized) && ctx.Err() == nil && c.revoke(key) {
+			continue
+		}
+		return err
 	}
-	return nil
 }
 
 // GetSuggestedStocks fetches stocks from the Polygon API based on a search query.
-// This method makes a SINGLE HTTP request to avoid rate limiting issues.
+// This returns only the first page of matches; use ListTickersPage or
+// ListTickers to follow pagination.
 func (c *PolygonClient) GetSuggestedStocks(ctx context.Context, query string) ([]models.DisplayStock, error) {
-	if err := c.waitForRateLimit(ctx); err != nil {
+	page, err := c.ListTickersPage(ctx, models.TickerListParams{Search: query, Market: "stocks"})
+	if err != nil {
 		return nil, err
 	}
-	log.Printf("GetSuggestedStocks called with query: '%s'", query)
+	return models.ToDisplayStocks(page.Results), nil
+}
+
+// ListTickersPage fetches one page of active tickers from the Polygon API.
+// Pass the previous page's NextCursor in params.Cursor to continue a listing.
+func (c *PolygonClient) ListTickersPage(ctx context.Context, params models.TickerListParams) (*models.TickerPage, error) {
+	key := cacheKey(methodListTickers, params.Search, params.Market, params.Type, strconv.Itoa(params.Limit), params.Cursor)
+	return coalesce(ctx, c.inflight, key, func(ctx context.Context) (*models.TickerPage, error) {
+		return c.fetchTickersPage(ctx, params)
+	})
+}
+
+// fetchTickersPage performs the HTTP request behind ListTickersPage.
+func (c *PolygonClient) fetchTickersPage(ctx context.Context, listParams models.TickerListParams) (*models.TickerPage, error) {
+	log.Printf("ListTickersPage called with search: '%s', market: '%s', cursor: %t", listParams.Search, listParams.Market, listParams.Cursor != "")
+
+	limit := listParams.Limit
+	if limit <= 0 || limit > 1000 {
+		limit = 1000
+	}
 
 	// Build the API URL with parameters
-	baseURL := "https://api.polygon.io/v3/reference/tickers"
+	baseURL := c.baseURL + "/v3/reference/tickers"
 	params := url.Values{}
-	params.Set("market", "stocks")
 	params.Set("active", "true")
 	params.Set("sort", "ticker")
 	params.Set("order", "asc")
-	params.Set("limit", "1000")
-	params.Set("apiKey", c.apiKey)
+	params.Set("limit", strconv.Itoa(limit))
 
-	// If a search query is provided, add it to the parameters
-	if query != "" {
-		params.Set("search", query)
-		log.Printf("Added search parameter: %s", query)
+	if listParams.Market != "" {
+		params.Set("market", listParams.Market)
 	}
-
-	// Construct the full URL
-	apiURL := fmt.Sprintf("%s?%s", baseURL, params.Encode())
-	log.Printf("Making single API request to: %s", baseURL) // Don't log API key
-
-	// Create the HTTP request
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	if listParams.Type != "" {
+		params.Set("type", listParams.Type)
 	}
-
-	// Execute the request
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+	// If a search query is provided, add it to the parameters
+	if listParams.Search != "" {
+		params.Set("search", listParams.Search)
 	}
-	defer resp.Body.Close()
-
-	// Check for HTTP errors
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status code: %d", resp.StatusCode)
+	// The cursor encodes the position in the listing Polygon gave us in next_url
+	if listParams.Cursor != "" {
+		params.Set("cursor", listParams.Cursor)
 	}
 
-	// Parse the JSON response
 	var apiResponse models.PolygonAPIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResponse); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := c.getJSON(ctx, baseURL, params, &apiResponse); err != nil {
+		return nil, err
 	}
 
-	log.Printf("API Response: Status=%s, Count=%d", apiResponse.Status, apiResponse.Count)
+	log.Printf("API Response: Status=%s, Count=%d, HasNext=%t", apiResponse.Status, apiResponse.Count, apiResponse.NextURL != "")
 
-	// Convert Polygon response to our DisplayStock model
-	var stocks []models.DisplayStock
-	for i, polygonTicker := range apiResponse.Results {
-		log.Printf("Processing ticker %d: %s - %s", i+1, polygonTicker.Ticker, polygonTicker.Name)
+	nextCursor, err := cursorFromNextURL(apiResponse.NextURL)
+	if err != nil {
+		return nil, err
+	}
 
-		displayStock := models.DisplayStock{
-			Ticker:          polygonTicker.Ticker,
-			Name:            polygonTicker.Name,
-			Shares:          0, // Default to 0 shares since this is for suggestions
-			PrimaryExchange: polygonTicker.PrimaryExchange,
-			CurrencyName:    polygonTicker.CurrencyName,
-		}
+	return &models.TickerPage{
+		Results:    apiResponse.Results,
+		NextCursor: nextCursor,
+	}, nil
+}
 
-		stocks = append(stocks, displayStock)
+// cursorFromNextURL extracts the cursor parameter from Polygon's next_url.
+// An empty next_url means the listing is complete.
+func cursorFromNextURL(nextURL string) (string, error) {
+	if nextURL == "" {
+		return "", nil
 	}
-
-	log.Printf("Successfully retrieved %d stocks in SINGLE API request", len(stocks))
-	return stocks, nil
+	parsed, err := url.Parse(nextURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse next_url: %w", err)
+	}
+	return parsed.Query().Get("cursor"), nil
 }
 
 // GetAggregates fetches historical OHLC data for a ticker from Polygon API.
@@ -118,100 +281,56 @@ func (c *PolygonClient) GetSuggestedStocks(ctx context.Context, query string) ([
 // timespan: size of the time window (e.g., "day", "week", "month")
 // from, to: date range in YYYY-MM-DD format
 func (c *PolygonClient) GetAggregates(ctx context.Context, ticker, multiplier, timespan, from, to string) (*models.AggregatesResponse, error) {
-	if err := c.waitForRateLimit(ctx); err != nil {
-		return nil, err
-	}
+	return coalesce(ctx, c.inflight, cacheKey(methodAggregates, ticker, multiplier, timespan, from, to), func(ctx context.Context) (*models.AggregatesResponse, error) {
+		return c.fetchAggregates(ctx, ticker, multiplier, timespan, from, to)
+	})
+}
+
+// fetchAggregates performs the HTTP request behind GetAggregates.
+func (c *PolygonClient) fetchAggregates(ctx context.Context, ticker, multiplier, timespan, from, to string) (*models.AggregatesResponse, error) {
 	log.Printf("GetAggregates called for ticker: %s, timespan: %s, from: %s, to: %s", ticker, timespan, from, to)
 
 	// Build the API URL
 	// GET /v2/aggs/ticker/{stocksTicker}/range/{multiplier}/{timespan}/{from}/{to}
-	baseURL := fmt.Sprintf("https://api.polygon.io/v2/aggs/ticker/%s/range/%s/%s/%s/%s",
-		ticker, multiplier, timespan, from, to)
+	baseURL := fmt.Sprintf("%s/v2/aggs/ticker/%s/range/%s/%s/%s/%s",
+		c.baseURL, ticker, multiplier, timespan, from, to)
 
 	params := url.Values{}
 	params.Set("adjusted", "true")
 	params.Set("sort", "asc")
-	params.Set("apiKey", c.apiKey)
-
-	apiURL := fmt.Sprintf("%s?%s", baseURL, params.Encode())
-	log.Printf("Making API request to: %s", baseURL)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status code: %d", resp.StatusCode)
-	}
+	params.Set("limit", "50000") // The maximum; the default of 5000 truncates long minute ranges
 
 	var apiResponse models.AggregatesResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResponse); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := c.getJSON(ctx, baseURL, params, &apiResponse); err != nil {
+		return nil, err
 	}
 
 	log.Printf("GetAggregates Response: Status=%s, ResultsCount=%d", apiResponse.Status, apiResponse.ResultsCount)
 	return &apiResponse, nil
 }
 
-// TickerNotFoundError is returned when a ticker doesn't exist in Polygon's database.
-type TickerNotFoundError struct {
-	Ticker string
-}
-
-func (e *TickerNotFoundError) Error() string {
-	return fmt.Sprintf("ticker not found: %s", e.Ticker)
-}
-
 // GetTickerDetails fetches detailed information about a ticker from Polygon API.
 func (c *PolygonClient) GetTickerDetails(ctx context.Context, ticker string) (*models.TickerDetails, error) {
-	if err := c.waitForRateLimit(ctx); err != nil {
-		return nil, err
-	}
+	return coalesce(ctx, c.inflight, cacheKey(methodTickerDetails, ticker), func(ctx context.Context) (*models.TickerDetails, error) {
+		return c.fetchTickerDetails(ctx, ticker)
+	})
+}
+
+// fetchTickerDetails performs the HTTP request behind GetTickerDetails.
+func (c *PolygonClient) fetchTickerDetails(ctx context.Context, ticker string) (*models.TickerDetails, error) {
 	log.Printf("GetTickerDetails called for ticker: %s", ticker)
 
 	// Build the API URL
 	// GET /v3/reference/tickers/{ticker}
-	baseURL := fmt.Sprintf("https://api.polygon.io/v3/reference/tickers/%s", ticker)
-
-	params := url.Values{}
-	params.Set("apiKey", c.apiKey)
-
-	apiURL := fmt.Sprintf("%s?%s", baseURL, params.Encode())
-	log.Printf("Making API request to: %s", baseURL)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		// Read the error response body for better debugging
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		log.Printf("Polygon API error for ticker %s: status=%d, body=%s", ticker, resp.StatusCode, string(bodyBytes))
+	baseURL := fmt.Sprintf("%s/v3/reference/tickers/%s", c.baseURL, ticker)
 
+	var apiResponse models.TickerDetailsResponse
+	if err := c.getJSON(ctx, baseURL, url.Values{}, &apiResponse); err != nil {
 		// Return a specific error type for 404 so handlers can respond appropriately
-		if resp.StatusCode == http.StatusNotFound {
+		if errors.Is(err, ErrNotFound) {
 			return nil, &TickerNotFoundError{Ticker: ticker}
 		}
-		return nil, fmt.Errorf("API request failed with status code: %d", resp.StatusCode)
-	}
-
-	var apiResponse models.TickerDetailsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResponse); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return nil, err
 	}
 
 	log.Printf("GetTickerDetails Response: Status=%s, Ticker=%s", apiResponse.Status, apiResponse.Results.Ticker)
@@ -220,49 +339,246 @@ func (c *PolygonClient) GetTickerDetails(ctx context.Context, ticker string) (*m
 
 // GetPreviousClose fetches the previous day's OHLC data for a ticker from Polygon API.
 func (c *PolygonClient) GetPreviousClose(ctx context.Context, ticker string) (*models.PreviousCloseResponse, error) {
-	if err := c.waitForRateLimit(ctx); err != nil {
-		return nil, err
-	}
+	return coalesce(ctx, c.inflight, cacheKey(methodPreviousClose, ticker), func(ctx context.Context) (*models.PreviousCloseResponse, error) {
+		return c.fetchPreviousClose(ctx, ticker)
+	})
+}
+
+// fetchPreviousClose performs the HTTP request behind GetPreviousClose.
+func (c *PolygonClient) fetchPreviousClose(ctx context.Context, ticker string) (*models.PreviousCloseResponse, error) {
 	log.Printf("GetPreviousClose called for ticker: %s", ticker)
 
 	// Build the API URL
 	// GET /v2/aggs/ticker/{stocksTicker}/prev
-	baseURL := fmt.Sprintf("https://api.polygon.io/v2/aggs/ticker/%s/prev", ticker)
+	baseURL := fmt.Sprintf("%s/v2/aggs/ticker/%s/prev", c.baseURL, ticker)
 
 	params := url.Values{}
 	params.Set("adjusted", "true")
-	params.Set("apiKey", c.apiKey)
 
-	apiURL := fmt.Sprintf("%s?%s", baseURL, params.Encode())
-	log.Printf("Making API request to: %s", baseURL)
+	var apiResponse models.PreviousCloseResponse
+	if err := c.getJSON(ctx, baseURL, params, &apiResponse); err != nil {
+		return nil, err
+	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	log.Printf("GetPreviousClose Response: Status=%s, ResultsCount=%d", apiResponse.Status, apiResponse.ResultsCount)
+	return &apiResponse, nil
+}
+
+// GetGroupedDaily fetches the daily OHLC bar for every US stock on a single date.
+// One request covers the whole market, so it is the cheapest way to price many tickers.
+// date: trading day in YYYY-MM-DD format
+func (c *PolygonClient) GetGroupedDaily(ctx context.Context, date string) (*models.GroupedDailyResponse, error) {
+	return coalesce(ctx, c.inflight, cacheKey(methodGroupedDaily, date), func(ctx context.Context) (*models.GroupedDailyResponse, error) {
+		return c.fetchGroupedDaily(ctx, date)
+	})
+}
+
+// fetchGroupedDaily performs the HTTP request behind GetGroupedDaily.
+func (c *PolygonClient) fetchGroupedDaily(ctx context.Context, date string) (*models.GroupedDailyResponse, error) {
+	log.Printf("GetGroupedDaily called for date: %s", date)
+
+	// Build the API URL
+	// GET /v2/aggs/grouped/locale/us/market/stocks/{date}
+	baseURL := fmt.Sprintf("%s/v2/aggs/grouped/locale/us/market/stocks/%s", c.baseURL, date)
+
+	params := url.Values{}
+	params.Set("adjusted", "true")
+
+	var apiResponse models.GroupedDailyResponse
+	if err := c.getJSON(ctx, baseURL, params, &apiResponse); err != nil {
+		return nil, err
 	}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+	log.Printf("GetGroupedDaily Response: Status=%s, ResultsCount=%d", apiResponse.Status, apiResponse.ResultsCount)
+	return &apiResponse, nil
+}
+
+// GetDividends fetches a ticker's cash dividend history from Polygon API, newest first.
+func (c *PolygonClient) GetDividends(ctx context.Context, ticker string) (*models.DividendsResponse, error) {
+	return coalesce(ctx, c.inflight, cacheKey(methodDividends, ticker), func(ctx context.Context) (*models.DividendsResponse, error) {
+		return c.fetchDividends(ctx, ticker)
+	})
+}
+
+// fetchDividends performs the HTTP request behind GetDividends.
+func (c *PolygonClient) fetchDividends(ctx context.Context, ticker string) (*models.DividendsResponse, error) {
+	log.Printf("GetDividends called for ticker: %s", ticker)
+
+	// Build the API URL
+	// GET /v3/reference/dividends?ticker={ticker}
+	baseURL := c.baseURL + "/v3/reference/dividends"
+
+	// One page covers decades of monthly payments
+	params := url.Values{}
+	params.Set("ticker", strings.ToUpper(ticker))
+	params.Set("sort", "ex_dividend_date")
+	params.Set("order", "desc")
+	params.Set("limit", "1000")
+
+	var apiResponse models.DividendsResponse
+	if err := c.getJSON(ctx, baseURL, params, &apiResponse); err != nil {
+		return nil, err
+	}
+	apiResponse.Ticker = strings.ToUpper(ticker)
+	if apiResponse.Results == nil {
+		apiResponse.Results = []models.Dividend{} // Polygon omits results for tickers that never paid
+	}
+
+	log.Printf("GetDividends Response: Status=%s, Count=%d", apiResponse.Status, len(apiResponse.Results))
+	return &apiResponse, nil
+}
+
+// GetSplits fetches a ticker's stock split history from Polygon API, newest first.
+func (c *PolygonClient) GetSplits(ctx context.Context, ticker string) (*models.SplitsResponse, error) {
+	return coalesce(ctx, c.inflight, cacheKey(methodSplits, ticker), func(ctx context.Context) (*models.SplitsResponse, error) {
+		return c.fetchSplits(ctx, ticker)
+	})
+}
+
+// fetchSplits performs the HTTP request behind GetSplits.
+func (c *PolygonClient) fetchSplits(ctx context.Context, ticker string) (*models.SplitsResponse, error) {
+	log.Printf("GetSplits called for ticker: %s", ticker)
+
+	// Build the API URL
+	// GET /v3/reference/splits?ticker={ticker}
+	baseURL := c.baseURL + "/v3/reference/splits"
+
+	params := url.Values{}
+	params.Set("ticker", strings.ToUpper(ticker))
+	params.Set("sort", "execution_date")
+	params.Set("order", "desc")
+	params.Set("limit", "1000")
+
+	var apiResponse models.SplitsResponse
+	if err := c.getJSON(ctx, baseURL, params, &apiResponse); err != nil {
+		return nil, err
+	}
+	apiResponse.Ticker = strings.ToUpper(ticker)
+	if apiResponse.Results == nil {
+		apiResponse.Results = []models.Split{}
+	}
+
+	log.Printf("GetSplits Response: Status=%s, Count=%d", apiResponse.Status, len(apiResponse.Results))
+	return &apiResponse, nil
+}
+
+// GetNews fetches one page of news articles about a ticker from Polygon API, newest first.
+// Pass the previous page's NextCursor in params.Cursor to continue.
+func (c *PolygonClient) GetNews(ctx context.Context, params models.NewsParams) (*models.NewsPage, error) {
+	key := cacheKey(methodNews, params.Ticker, strconv.Itoa(params.Limit), params.Cursor)
+	return coalesce(ctx, c.inflight, key, func(ctx context.Context) (*models.NewsPage, error) {
+		return c.fetchNews(ctx, params)
+	})
+}
+
+// fetchNews performs the HTTP request behind GetNews.
+func (c *PolygonClient) fetchNews(ctx context.Context, newsParams models.NewsParams) (*models.NewsPage, error) {
+	log.Printf("GetNews called for ticker: %s, cursor: %t", newsParams.Ticker, newsParams.Cursor != "")
+
+	limit := newsParams.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	limit = min(limit, 1000)
+
+	// Build the API URL
+	// GET /v2/reference/news?ticker={ticker}
+	baseURL := c.baseURL + "/v2/reference/news"
+	params := url.Values{}
+	params.Set("ticker", strings.ToUpper(newsParams.Ticker))
+	params.Set("sort", "published_utc")
+	params.Set("order", "desc")
+	params.Set("limit", strconv.Itoa(limit))
+	if newsParams.Cursor != "" {
+		params.Set("cursor", newsParams.Cursor)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status code: %d", resp.StatusCode)
+	var apiResponse models.NewsResponse
+	if err := c.getJSON(ctx, baseURL, params, &apiResponse); err != nil {
+		return nil, err
 	}
 
-	// Read the body for debugging
-	bodyBytes, err := io.ReadAll(resp.Body)
+	log.Printf("GetNews Response: Status=%s, Count=%d, HasNext=%t", apiResponse.Status, apiResponse.Count, apiResponse.NextURL != "")
+
+	nextCursor, err := cursorFromNextURL(apiResponse.NextURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, err
+	}
+	if apiResponse.Results == nil {
+		apiResponse.Results = []models.NewsArticle{}
 	}
-	log.Printf("GetPreviousClose raw response: %s", string(bodyBytes))
 
-	var apiResponse models.PreviousCloseResponse
-	if err := json.Unmarshal(bodyBytes, &apiResponse); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	return &models.NewsPage{
+		Results:    apiResponse.Results,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// financialsLimit is how many periods of statements GetFinancials returns: five years of quarters
+const financialsLimit = 20
+
+// GetFinancials fetches a ticker's income statement, balance sheet, and cash flow
+// statement from Polygon API, newest period first. timeframe is quarterly or annual.
+func (c *PolygonClient) GetFinancials(ctx context.Context, ticker, timeframe string) (*models.FinancialsResponse, error) {
+	return coalesce(ctx, c.inflight, cacheKey(methodFinancials, ticker, timeframe), func(ctx context.Context) (*models.FinancialsResponse, error) {
+		return c.fetchFinancials(ctx, ticker, timeframe)
+	})
+}
+
+// fetchFinancials performs the HTTP request behind GetFinancials.
+func (c *PolygonClient) fetchFinancials(ctx context.Context, ticker, timeframe string) (*models.FinancialsResponse, error) {
+	log.Printf("GetFinancials called for ticker: %s, timeframe: %s", ticker, timeframe)
+
+	// Build the API URL
+	// GET /vX/reference/financials?ticker={ticker}&timeframe={timeframe}
+	baseURL := c.baseURL + "/vX/reference/financials"
+
+	params := url.Values{}
+	params.Set("ticker", strings.ToUpper(ticker))
+	params.Set("timeframe", timeframe)
+	params.Set("sort", "period_of_report_date")
+	params.Set("order", "desc")
+	params.Set("limit", strconv.Itoa(financialsLimit))
+
+	var apiResponse models.FinancialsResponse
+	if err := c.getJSON(ctx, baseURL, params, &apiResponse); err != nil {
+		return nil, err
+	}
+	apiResponse.Ticker = strings.ToUpper(ticker)
+	apiResponse.Timeframe = timeframe
+	if apiResponse.Results == nil {
+		apiResponse.Results = []models.FinancialReport{}
 	}
 
-	log.Printf("GetPreviousClose Response: Status=%s, ResultsCount=%d", apiResponse.Status, apiResponse.ResultsCount)
+	log.Printf("GetFinancials Response: Status=%s, Count=%d", apiResponse.Status, len(apiResponse.Results))
 	return &apiResponse, nil
 }
+
+// GetMarketStatus fetches whether the markets are open right now from Polygon API.
+func (c *PolygonClient) GetMarketStatus(ctx context.Context) (*models.MarketStatusResponse, error) {
+	return coalesce(ctx, c.inflight, cacheKey(methodMarketStatus), func(ctx context.Context) (*models.MarketStatusResponse, error) {
+		// GET /v1/marketstatus/now
+		var apiResponse models.MarketStatusResponse
+		if err := c.getJSON(ctx, c.baseURL+"/v1/marketstatus/now", url.Values{}, &apiResponse); err != nil {
+			return nil, err
+		}
+		log.Printf("GetMarketStatus Response: Market=%s", apiResponse.Market)
+		return &apiResponse, nil
+	})
+}
+
+// GetMarketHolidays fetches upcoming exchange holidays and early closes from Polygon API.
+func (c *PolygonClient) GetMarketHolidays(ctx context.Context) (*models.MarketHolidaysResponse, error) {
+	return coalesce(ctx, c.inflight, cacheKey(methodMarketHolidays), func(ctx context.Context) (*models.MarketHolidaysResponse, error) {
+		// GET /v1/marketstatus/upcoming answers with a bare array
+		var holidays []models.MarketHoliday
+		if err := c.getJSON(ctx, c.baseURL+"/v1/marketstatus/upcoming", url.Values{}, &holidays); err != nil {
+			return nil, err
+		}
+		if holidays == nil {
+			holidays = []models.MarketHoliday{}
+		}
+		log.Printf("GetMarketHolidays Response: Count=%d", len(holidays))
+		return &models.MarketHolidaysResponse{Results: holidays}, nil
+	})
+}
@@ -0,0 +1,277 @@
+package clients
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/cole-zoom/dUW-app/api/internal/models"
+)
+
+// RecordingClient passes every call through to another APIClient and saves the
+// responses in the FixtureClient layout, so a session against a live provider
+// can be replayed offline later. Bars and tickers are merged into existing files.
+type RecordingClient struct {
+	next APIClient
+	dir  string
+	mu   sync.Mutex // Serializes read-merge-write cycles on fixture files
+}
+
+func NewRecordingClient(next APIClient, dir string) *RecordingClient {
+	return &RecordingClient{next: next, dir: dir}
+}
+
+// GetSuggestedStocks goes through ListTickersPage so the matching tickers are recorded
+func (c *RecordingClient) GetSuggestedStocks(ctx context.Context, query string) ([]models.DisplayStock, error) {
+	page, err := c.ListTickersPage(ctx, models.TickerListParams{Search: query, Market: "stocks"})
+	if err != nil {
+		return nil, err
+	}
+	return models.ToDisplayStocks(page.Results), nil
+}
+
+func (c *RecordingClient) ListTickersPage(ctx context.Context, params models.TickerListParams) (*models.TickerPage, error) {
+	page, err := c.next.ListTickersPage(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	c.record("tickers", func() error { return c.mergeTickers(page.Results) })
+	return page, nil
+}
+
+func (c *RecordingClient) GetAggregates(ctx context.Context, ticker, multiplier, timespan, from, to string) (*models.AggregatesResponse, error) {
+	resp, err := c.next.GetAggregates(ctx, ticker, multiplier, timespan, from, to)
+	if err != nil {
+		return nil, err
+	}
+	c.record("aggregates for "+ticker, func() error { return c.mergeBars(ticker, multiplier, timespan, resp.Results) })
+	return resp, nil
+}
+
+func (c *RecordingClient) GetTickerDetails(ctx context.Context, ticker string) (*models.TickerDetails, error) {
+	details, err := c.next.GetTickerDetails(ctx, ticker)
+	if err != nil {
+		return nil, err
+	}
+	c.record("details for "+ticker, func() error {
+		return writeFixtureJSON(filepath.Join(c.dir, "details", fixtureName(ticker)+".json"), details)
+	})
+	return details, nil
+}
+
+func (c *RecordingClient) GetPreviousClose(ctx context.Context, ticker string) (*models.PreviousCloseResponse, error) {
+	resp, err := c.next.GetPreviousClose(ctx, ticker)
+	if err != nil {
+		return nil, err
+	}
+	c.record("previous close for "+ticker, func() error {
+		return writeFixtureJSON(filepath.Join(c.dir, "previous", fixtureName(ticker)+".json"), resp)
+	})
+	return resp, nil
+}
+
+func (c *RecordingClient) GetGroupedDaily(ctx context.Context, date string) (*models.GroupedDailyResponse, error) {
+	resp, err := c.next.GetGroupedDaily(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+	c.record("grouped daily for "+date, func() error {
+		return writeFixtureJSON(filepath.Join(c.dir, "grouped", date+".json"), resp)
+	})
+	return resp, nil
+}
+
+func (c *RecordingClient) GetDividends(ctx context.Context, ticker string) (*models.DividendsResponse, error) {
+	resp, err := c.next.GetDividends(ctx, ticker)
+	if err != nil {
+		return nil, err
+	}
+	c.record("dividends for "+ticker, func() error {
+		return writeFixtureJSON(filepath.Join(c.dir, "dividends", fixtureName(ticker)+".json"), resp)
+	})
+	return resp, nil
+}
+
+func (c *RecordingClient) GetSplits(ctx context.Context, ticker string) (*models.SplitsResponse, error) {
+	resp, err := c.next.GetSplits(ctx, ticker)
+	if err != nil {
+		return nil, err
+	}
+	c.record("splits for "+ticker, func() error {
+		return writeFixtureJSON(filepath.Join(c.dir, "splits", fixtureName(ticker)+".json"), resp)
+	})
+	return resp, nil
+}
+
+func (c *RecordingClient) GetFinancials(ctx context.Context, ticker, timeframe string) (*models.FinancialsResponse, error) {
+	resp, err := c.next.GetFinancials(ctx, ticker, timeframe)
+	if err != nil {
+		return nil, err
+	}
+	c.record("financials for "+ticker, func() error {
+		return writeFixtureJSON(financialsFixturePath(c.dir, ticker, timeframe), resp)
+	})
+	return resp, nil
+}
+
+func (c *RecordingClient) GetMarketStatus(ctx context.Context) (*models.MarketStatusResponse, error) {
+	resp, err := c.next.GetMarketStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.record("market status", func() error {
+		return writeFixtureJSON(filepath.Join(c.dir, "market", "status.json"), resp)
+	})
+	return resp, nil
+}
+
+func (c *RecordingClient) GetMarketHolidays(ctx context.Context) (*models.MarketHolidaysResponse, error) {
+	resp, err := c.next.GetMarketHolidays(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.record("market holidays", func() error {
+		return writeFixtureJSON(filepath.Join(c.dir, "market", "holidays.json"), resp)
+	})
+	return resp, nil
+}
+
+func (c *RecordingClient) GetNews(ctx context.Context, params models.NewsParams) (*models.NewsPage, error) {
+	page, err := c.next.GetNews(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	c.record("news for "+params.Ticker, func() error { return c.mergeNews(params.Ticker, page.Results) })
+	return page, nil
+}
+
+// record runs a write under the lock; failures are logged so recording never breaks a request
+func (c *RecordingClient) record(what string, write func() error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := write(); err != nil {
+		log.Printf("RecordingClient - Failed to record %s: %v", what, err)
+	}
+}
+
+// mergeTickers adds or replaces tickers in tickers.json
+func (c *RecordingClient) mergeTickers(tickers []models.PolygonTickerResponse) error {
+	if len(tickers) == 0 {
+		return nil
+	}
+	existing, err := loadFixtureTickers(c.dir)
+	if err != nil {
+		return err
+	}
+
+	byTicker := make(map[string]models.PolygonTickerResponse, len(existing)+len(tickers))
+	for _, t := range existing {
+		byTicker[t.Ticker] = t
+	}
+	for _, t := range tickers {
+		byTicker[t.Ticker] = t
+	}
+
+	merged := make([]models.PolygonTickerResponse, 0, len(byTicker))
+	for _, t := range byTicker {
+		merged = append(merged, t)
+	}
+	slices.SortFunc(merged, func(a, b models.PolygonTickerResponse) int { return strings.Compare(a.Ticker, b.Ticker) })
+	return writeFixtureJSON(filepath.Join(c.dir, "tickers.json"), merged)
+}
+
+// mergeBars adds bars to a ticker's aggregates file, newer data replacing bars with the same timestamp
+func (c *RecordingClient) mergeBars(ticker, multiplier, timespan string, bars []models.AggregateBar) error {
+	if len(bars) == 0 {
+		return nil
+	}
+	existing, err := loadFixtureBars(c.dir, ticker, multiplier, timespan)
+	if err != nil {
+		return err
+	}
+
+	byTimestamp := make(map[models.FlexibleInt64]models.AggregateBar, len(existing)+len(bars))
+	for _, bar := range existing {
+		byTimestamp[bar.Timestamp] = bar
+	}
+	for _, bar := range bars {
+		byTimestamp[bar.Timestamp] = bar
+	}
+
+	merged := make([]models.AggregateBar, 0, len(byTimestamp))
+	for _, bar := range byTimestamp {
+		merged = append(merged, bar)
+	}
+	slices.SortFunc(merged, func(a, b models.AggregateBar) int { return cmp.Compare(a.Timestamp, b.Timestamp) })
+
+	return writeFixtureJSON(aggregatesFixtureBase(c.dir, ticker, multiplier, timespan)+".json", models.AggregatesResponse{
+		Ticker:       strings.ToUpper(ticker),
+		QueryCount:   len(merged),
+		ResultsCount: len(merged),
+		Adjusted:     true,
+		Results:      merged,
+		Status:       "OK",
+	})
+}
+
+// mergeNews adds articles to a ticker's news file, newer copies replacing articles with the same ID
+func (c *RecordingClient) mergeNews(ticker string, articles []models.NewsArticle) error {
+	if len(articles) == 0 {
+		return nil
+	}
+	path := newsFixturePath(c.dir, ticker)
+	var existing []models.NewsArticle
+	if _, err := readFixtureJSON(path, &existing); err != nil {
+		return err
+	}
+
+	byID := make(map[string]models.NewsArticle, len(existing)+len(articles))
+	for _, article := range existing {
+		byID[article.ID] = article
+	}
+	for _, article := range articles {
+		byID[article.ID] = article
+	}
+
+	merged := make([]models.NewsArticle, 0, len(byID))
+	for _, article := range byID {
+		merged = append(merged, article)
+	}
+	slices.SortFunc(merged, func(a, b models.NewsArticle) int {
+		return cmp.Or(strings.Compare(b.PublishedUTC, a.PublishedUTC), strings.Compare(a.ID, b.ID))
+	})
+	return writeFixtureJSON(path, merged)
+}
+
+// writeFixtureJSON writes v as indented JSON via a temp file so readers never see a partial fixture
+func writeFixtureJSON(path string, v any) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create fixture directory: %w", err)
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode fixture: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".fixture-*")
+	if err != nil {
+		return fmt.Errorf("failed to create fixture: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
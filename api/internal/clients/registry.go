@@ -0,0 +1,75 @@
+package clients
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ProviderConfig carries the settings a market data provider needs to start.
+// Zero values leave the provider's defaults in place.
+type ProviderConfig struct {
+	APIKeys           []string      // Polygon rotates through all of them; other providers use the first
+	RequestsPerMinute int           // Plan limit, per key
+	Burst             int           // Requests allowed at once before the limit applies
+	BaseURL           string        // API root, e.g. for a proxy
+	Timeout           time.Duration // Per HTTP request
+	FixturesDir       string        // Directory read by the fixtures provider
+}
+
+// ProviderFactory builds an APIClient for one market data vendor
+type ProviderFactory func(cfg ProviderConfig) (APIClient, error)
+
+// providers maps provider names, as used in configuration, to their factories
+var providers = map[string]ProviderFactory{
+	"polygon": func(cfg ProviderConfig) (APIClient, error) {
+		if len(cfg.APIKeys) == 0 {
+			return nil, fmt.Errorf("polygon provider requires an API key")
+		}
+		return NewPolygonClient(PolygonConfig{
+			APIKeys:           cfg.APIKeys,
+			RequestsPerMinute: cfg.RequestsPerMinute,
+			Burst:             cfg.Burst,
+			BaseURL:           cfg.BaseURL,
+			Timeout:           cfg.Timeout,
+		}), nil
+	},
+	"finnhub": func(cfg ProviderConfig) (APIClient, error) {
+		if len(cfg.APIKeys) == 0 {
+			return nil, fmt.Errorf("finnhub provider requires an API key")
+		}
+		return NewFinnhubClient(FinnhubConfig{
+			APIKey:            cfg.APIKeys[0],
+			RequestsPerMinute: cfg.RequestsPerMinute,
+			Burst:             cfg.Burst,
+			BaseURL:           cfg.BaseURL,
+			Timeout:           cfg.Timeout,
+		}), nil
+	},
+	"fixtures": func(cfg ProviderConfig) (APIClient, error) {
+		if cfg.FixturesDir == "" {
+			return nil, fmt.Errorf("fixtures provider requires a fixtures directory")
+		}
+		return NewFixtureClient(cfg.FixturesDir), nil
+	},
+}
+
+// NewProvider builds the named market data provider
+func NewProvider(name string, cfg ProviderConfig) (APIClient, error) {
+	factory, ok := providers[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown market data provider %q (available: %s)", name, strings.Join(ProviderNames(), ", "))
+	}
+	return factory(cfg)
+}
+
+// ProviderNames lists the registered providers in sorted order
+func ProviderNames() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,121 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy bounds how transient upstream failures (429, 5xx, network errors) are retried
+type RetryPolicy struct {
+	MaxAttempts int           // Including the first try
+	BaseDelay   time.Duration // Backoff before the first retry, doubled for each one after
+	MaxDelay    time.Duration // Longest single wait; a longer Retry-After is returned to the caller instead
+}
+
+// DefaultRetryPolicy returns the retry settings used in production
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    30 * time.Second,
+	}
+}
+
+// fetchJSON sends the request built by newRequest, retrying transient failures
+// with jittered exponential backoff (or the server's Retry-After), and decodes a
+// 200 response into out. wait is called before every attempt, e.g. for rate limiting.
+func fetchJSON(ctx context.Context, httpClient *http.Client, policy RetryPolicy, wait func(context.Context) error, newRequest func(context.Context) (*http.Request, error), out any) error {
+	for attempt := 1; ; attempt++ {
+		if err := wait(ctx); err != nil {
+			return err
+		}
+
+		req, err := newRequest(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+
+		err = doJSON(httpClient, req, out)
+		if err == nil || ctx.Err() != nil || !isRetryable(err) || attempt >= policy.MaxAttempts {
+			return err
+		}
+
+		delay := RetryAfter(err)
+		if delay == 0 {
+			delay = backoff(policy.BaseDelay, attempt)
+		}
+		if delay > policy.MaxDelay {
+			return err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			return err // Waiting would outlive the caller anyway
+		}
+
+		log.Printf("Retrying %s in %v (attempt %d of %d): %v", req.URL.Path, delay.Round(time.Millisecond), attempt+1, policy.MaxAttempts, err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// doJSON performs one request and decodes a 200 response body into out
+func doJSON(httpClient *http.Client, req *http.Request, out any) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w: %w", ErrUpstreamUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// Read some of the error body for better debugging
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		log.Printf("API error from %s: status=%d, body=%s", req.URL.Path, resp.StatusCode, string(body))
+		return &StatusError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// isRetryable reports whether the same request might succeed if sent again
+func isRetryable(err error) bool {
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrUpstreamUnavailable)
+}
+
+// backoff returns the delay before retry number attempt: half the exponential
+// step plus a random share of the other half, so concurrent callers spread out
+func backoff(base time.Duration, attempt int) time.Duration {
+	step := base << (attempt - 1)
+	return step/2 + rand.N(step/2+1)
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
@@ -0,0 +1,318 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Priority orders requests competing for upstream rate limit tokens
+type Priority int
+
+const (
+	PriorityInteractive Priority = iota // A user is waiting on a single response (charts, details)
+	PriorityBulk                        // Many calls on behalf of one request, e.g. valuing a portfolio
+	PriorityBackground                  // Scheduled jobs such as the securities sync
+	numPriorities
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityInteractive:
+		return "interactive"
+	case PriorityBulk:
+		return "bulk"
+	case PriorityBackground:
+		return "background"
+	default:
+		return fmt.Sprintf("priority(%d)", int(p))
+	}
+}
+
+type priorityKey struct{}
+type admissionDeadlineKey struct{}
+
+// WithPriority tags upstream calls made with ctx; untagged calls are interactive
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+func priorityFrom(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok && p >= 0 && p < numPriorities {
+		return p
+	}
+	return PriorityInteractive
+}
+
+// requesterFrom returns the user a call is made for, as set by the auth middleware;
+// calls outside a request (jobs) share the empty key
+func requesterFrom(ctx context.Context) string {
+	userID, _ := ctx.Value("userID").(string)
+	return userID
+}
+
+// withAdmissionDeadline remembers a caller's deadline on a context that has lost it,
+// such as a coalesced call, so the scheduler can still reject hopeless waits
+func withAdmissionDeadline(ctx context.Context, deadline time.Time) context.Context {
+	return context.WithValue(ctx, admissionDeadlineKey{}, deadline)
+}
+
+func admissionDeadline(ctx context.Context) (time.Time, bool) {
+	if deadline, ok := ctx.Deadline(); ok {
+		return deadline, true
+	}
+	deadline, ok := ctx.Value(admissionDeadlineKey{}).(time.Time)
+	return deadline, ok
+}
+
+// QueueStats describes the requests waiting at one priority
+type QueueStats struct {
+	Priority      string `json:"priority"`
+	Depth         int    `json:"depth"`
+	EstimatedWait string `json:"estimated_wait"` // For a request arriving now at this priority
+}
+
+// SchedulerStats is a snapshot of a Scheduler for the health endpoint
+type SchedulerStats struct {
+	Key           string       `json:"key,omitempty"` // Masked API key, for providers with a key pool
+	Revoked       bool         `json:"revoked,omitempty"`
+	RatePerMinute float64      `json:"rate_per_minute"`
+	Burst         int          `json:"burst"`
+	Available     float64      `json:"available_tokens"`
+	Queues        []QueueStats `json:"queues"`
+}
+
+// RateLimitReporter is implemented by providers that queue requests behind a Scheduler
+type RateLimitReporter interface {
+	RateLimitStats() []SchedulerStats
+}
+
+// Scheduler is a token bucket that hands tokens to waiting requests by priority
+// rather than arrival order. Within a priority, callers are served round-robin by
+// user, so one user's burst can't starve another's requests.
+type Scheduler struct {
+	rate  float64 // Tokens per second
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	queues [numPriorities]*fairQueue
+	timer  *time.Timer
+}
+
+type schedulerWaiter struct {
+	ready   chan struct{}
+	key     string
+	granted bool
+}
+
+// fairQueue holds waiters grouped by fairness key, served round-robin
+type fairQueue struct {
+	order []string // Keys with waiters, in service order
+	byKey map[string][]*schedulerWaiter
+	size  int
+}
+
+// NewScheduler allows one request per `every` on average, with bursts of up to burst
+func NewScheduler(every time.Duration, burst int) *Scheduler {
+	s := &Scheduler{
+		rate:   1 / every.Seconds(),
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+	for i := range s.queues {
+		s.queues[i] = &fairQueue{byKey: make(map[string][]*schedulerWaiter)}
+	}
+	return s
+}
+
+// Wait blocks until the request may be sent. It fails immediately, with an error
+// wrapping ErrAdmissionRejected, if the caller's deadline would pass before its turn.
+func (s *Scheduler) Wait(ctx context.Context) error {
+	priority := priorityFrom(ctx)
+	key := requesterFrom(ctx)
+
+	s.mu.Lock()
+	s.refillLocked(time.Now())
+	if s.queuedLocked() == 0 && s.tokens >= 1 {
+		s.tokens--
+		s.mu.Unlock()
+		return nil
+	}
+
+	if deadline, ok := admissionDeadline(ctx); ok {
+		if wait := s.estimateLocked(priority); time.Now().Add(wait).After(deadline) {
+			s.mu.Unlock()
+			return fmt.Errorf("%w: estimated wait of %v exceeds the request deadline", ErrAdmissionRejected, wait.Round(time.Second))
+		}
+	}
+
+	w := &schedulerWaiter{ready: make(chan struct{}), key: key}
+	s.queues[priority].push(w)
+	s.dispatchLocked()
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if w.granted {
+			// Granted as we gave up; hand the token to the next waiter
+			s.tokens = math.Min(s.burst, s.tokens+1)
+		} else {
+			s.queues[priority].remove(w)
+		}
+		s.dispatchLocked()
+		return ctx.Err()
+	}
+}
+
+// Stats returns queue depths and wait estimates per priority
+func (s *Scheduler) Stats() SchedulerStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refillLocked(time.Now())
+
+	stats := SchedulerStats{
+		RatePerMinute: s.rate * 60,
+		Burst:         int(s.burst),
+		Available:     math.Floor(s.tokens*100) / 100,
+	}
+	for p := Priority(0); p < numPriorities; p++ {
+		stats.Queues = append(stats.Queues, QueueStats{
+			Priority:      p.String(),
+			Depth:         s.queues[p].size,
+			EstimatedWait: s.estimateLocked(p).Round(time.Second).String(),
+		})
+	}
+	return stats
+}
+
+// estimate is how long a request made with ctx would wait if it called Wait now
+func (s *Scheduler) estimate(ctx context.Context) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refillLocked(time.Now())
+	return s.estimateLocked(priorityFrom(ctx))
+}
+
+// refillLocked adds the tokens earned since the last refill
+func (s *Scheduler) refillLocked(now time.Time) {
+	s.tokens = math.Min(s.burst, s.tokens+now.Sub(s.last).Seconds()*s.rate)
+	s.last = now
+}
+
+func (s *Scheduler) queuedLocked() int {
+	total := 0
+	for _, q := range s.queues {
+		total += q.size
+	}
+	return total
+}
+
+// estimateLocked is how long a new request at priority p would wait: everyone
+// already queued at the same or higher priority goes first.
+func (s *Scheduler) estimateLocked(p Priority) time.Duration {
+	ahead := 0
+	for i := Priority(0); i <= p; i++ {
+		ahead += s.queues[i].size
+	}
+	needed := float64(ahead+1) - s.tokens
+	if needed <= 0 {
+		return 0
+	}
+	return time.Duration(needed / s.rate * float64(time.Second))
+}
+
+// dispatchLocked grants available tokens to waiters, highest priority first, and
+// arms a timer for when the next token is earned if anyone is still waiting
+func (s *Scheduler) dispatchLocked() {
+	s.refillLocked(time.Now())
+	for s.tokens >= 1 {
+		w := s.nextLocked()
+		if w == nil {
+			break
+		}
+		s.tokens--
+		w.granted = true
+		close(w.ready)
+	}
+
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if s.queuedLocked() > 0 {
+		wait := time.Duration((1 - s.tokens) / s.rate * float64(time.Second))
+		s.timer = time.AfterFunc(wait, func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.dispatchLocked()
+		})
+	}
+}
+
+func (s *Scheduler) nextLocked() *schedulerWaiter {
+	for _, q := range s.queues {
+		if w := q.pop(); w != nil {
+			return w
+		}
+	}
+	return nil
+}
+
+func (q *fairQueue) push(w *schedulerWaiter) {
+	if len(q.byKey[w.key]) == 0 {
+		q.order = append(q.order, w.key)
+	}
+	q.byKey[w.key] = append(q.byKey[w.key], w)
+	q.size++
+}
+
+// pop takes the oldest waiter of the next key in turn, then moves that key to the back
+func (q *fairQueue) pop() *schedulerWaiter {
+	if len(q.order) == 0 {
+		return nil
+	}
+	key := q.order[0]
+	q.order = q.order[1:]
+
+	waiters := q.byKey[key]
+	w := waiters[0]
+	if len(waiters) > 1 {
+		q.byKey[key] = waiters[1:]
+		q.order = append(q.order, key)
+	} else {
+		delete(q.byKey, key)
+	}
+	q.size--
+	return w
+}
+
+func (q *fairQueue) remove(w *schedulerWaiter) {
+	waiters := q.byKey[w.key]
+	for i, candidate := range waiters {
+		if candidate != w {
+			continue
+		}
+		q.size--
+		if len(waiters) == 1 {
+			delete(q.byKey, w.key)
+			for j, key := range q.order {
+				if key == w.key {
+					q.order = append(q.order[:j], q.order[j+1:]...)
+					break
+				}
+			}
+		} else {
+			q.byKey[w.key] = append(waiters[:i:i], waiters[i+1:]...)
+		}
+		return
+	}
+}
@@ -0,0 +1,86 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cole-zoom/dUW-app/api/internal/middleware"
+	"github.com/cole-zoom/dUW-app/api/internal/models"
+)
+
+func TestSchedulerRejectsRequestsThatCantMeetDeadline(t *testing.T) {
+	// One token, then one a minute: the next request waits far longer than any request deadline
+	scheduler := NewScheduler(time.Minute, 1)
+	if err := scheduler.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	inflight := newCoalescer()
+
+	tests := []struct {
+		name string
+		wait func(ctx context.Context) error
+	}{
+		{"direct", scheduler.Wait},
+		{"coalesced", func(ctx context.Context) error {
+			_, err := coalesce(ctx, inflight, "key", func(ctx context.Context) (struct{}, error) {
+				return struct{}{}, scheduler.Wait(ctx)
+			})
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			var elapsed time.Duration
+			handler := middleware.Deadline(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				start := time.Now()
+				err = tt.wait(r.Context())
+				elapsed = time.Since(start)
+			}), 5*time.Second)
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/stocks/AAPL/aggregates", nil))
+
+			if !errors.Is(err, ErrAdmissionRejected) {
+				t.Fatalf("err = %v, want ErrAdmissionRejected", err)
+			}
+			if elapsed > time.Second {
+				t.Errorf("rejected after %v; want no wait", elapsed)
+			}
+		})
+	}
+}
+
+// splitsError fails every splits lookup with err
+type splitsError struct {
+	APIClient
+	err error
+}
+
+func (p splitsError) GetSplits(ctx context.Context, ticker string) (*models.SplitsResponse, error) {
+	return nil, p.err
+}
+
+func TestAdmissionRejectionDoesNotCoolProviderDown(t *testing.T) {
+	rejected := fmt.Errorf("rate limit wait failed: %w", ErrAdmissionRejected)
+	client := NewFailoverClient([]NamedProvider{
+		{Name: "busy", Client: splitsError{err: rejected}},
+		{Name: "spare", Client: splitsError{err: rejected}},
+	}, time.Minute)
+
+	_, err := client.GetSplits(context.Background(), "AAPL")
+	if !errors.Is(err, ErrAdmissionRejected) {
+		t.Fatalf("err = %v, want ErrAdmissionRejected", err)
+	}
+	for _, health := range client.Health() {
+		if !health.Healthy {
+			t.Errorf("%s cooling down after its queue turned a request away", health.Name)
+		}
+		if health.FailoverCount != 1 {
+			t.Errorf("%s failovers = %d, want 1", health.Name, health.FailoverCount)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// GetMarketStatus is the HTTP handler for whether the market is open, with today's
+// session and upcoming holidays and early closes.
+// GET /api/market/status
+func (h *StockAPIHandler) GetMarketStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := h.stockService.MarketStatus(r.Context())
+	if err != nil {
+		log.Printf("Error getting market status: %v", err)
+		sendUpstreamError(w, fmt.Sprintf("Failed to get market status: %v", err), err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/cole-zoom/dUW-app/api/internal/clients"
+	"github.com/cole-zoom/dUW-app/api/internal/models"
+	"github.com/cole-zoom/dUW-app/api/internal/services"
+	"github.com/cole-zoom/dUW-app/api/internal/services/pnl"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PerformanceHandler serves cost basis and gain/loss reports. It needs both the
+// database (for the ledger) and the stock service (for market prices).
+type PerformanceHandler struct {
+	db           *pgxpool.Pool
+	stockService *services.StockService
+}
+
+// NewPerformanceHandler creates a new performance handler
+func NewPerformanceHandler(db *pgxpool.Pool, s *services.StockService) *PerformanceHandler {
+	return &PerformanceHandler{
+		db:           db,
+		stockService: s,
+	}
+}
+
+// GetPerformance --> GET /api/portfolios/{id}/performance?method={fifo|lifo|average_cost|specific_lot}
+// Uses the portfolio's cost basis method unless one is given in the query.
+func (h *PerformanceHandler) GetPerformance(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, ok := ctx.Value("userID").(string)
+	if !ok {
+		h.sendErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	portfolioID := r.PathValue("id")
+
+	var method pnl.Method
+	err := h.db.QueryRow(ctx, "SELECT cost_basis_method FROM portfolios WHERE id = $1 AND user_id = $2", portfolioID, userID).Scan(&method)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			h.sendErrorResponse(w, "Portfolio not found or access denied", http.StatusNotFound)
+			return
+		}
+		log.Printf("GetPerformance - Failed to load portfolio %s for userID %s: %v", portfolioID, userID, err)
+		h.sendErrorResponse(w, "Failed to fetch portfolio", http.StatusInternalServerError)
+		return
+	}
+
+	if override := r.URL.Query().Get("method"); override != "" {
+		method = pnl.Method(override)
+	}
+	if !method.Valid() {
+		h.sendErrorResponse(w, "method must be one of fifo, lifo, average_cost, specific_lot", http.StatusBadRequest)
+		return
+	}
+
+	stocks, ledgers, err := loadPortfolioLedgers(ctx, h.db, portfolioID)
+	if err != nil {
+		log.Printf("GetPerformance - Failed to load ledger for portfolio %s: %v", portfolioID, err)
+		h.sendErrorResponse(w, "Failed to fetch transactions", http.StatusInternalServerError)
+		return
+	}
+
+	// Pricing every open position is bulk work; single-ticker lookups go first
+	priceCtx := clients.WithPriority(ctx, clients.PriorityBulk)
+	positions := make([]*pnl.Position, 0, len(stocks))
+	for _, stock := range stocks {
+		position, err := pnl.Calculate(stock.Ticker, ledgers[stock.ID], method)
+		if err != nil {
+			log.Printf("GetPerformance - Failed to calculate %s in portfolio %s: %v", stock.Ticker, portfolioID, err)
+			h.sendErrorResponse(w, fmt.Sprintf("Failed to calculate %s: %v", stock.Ticker, err), http.StatusUnprocessableEntity)
+			return
+		}
+
+		// Closed positions only carry realized gains; don't spend a rate-limit token on them
+		if position.Shares > 0 {
+			price, err := h.stockService.GetLatestPrice(priceCtx, stock.Ticker)
+			if err != nil {
+				log.Printf("GetPerformance - Failed to price %s: %v", stock.Ticker, err)
+			} else {
+				position.Mark(price)
+			}
+		}
+		positions = append(positions, position)
+	}
+
+	response := models.APIResponse{Success: true, Data: pnl.Summarize(method, positions)}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetIncome --> GET /api/portfolios/{id}/income
+// Projects the next 12 months of dividend income by month, with trailing yield and
+// yield on cost per holding. Cost basis follows the portfolio's cost basis method.
+func (h *PerformanceHandler) GetIncome(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, ok := ctx.Value("userID").(string)
+	if !ok {
+		h.sendErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	portfolioID := r.PathValue("id")
+
+	var method pnl.Method
+	err := h.db.QueryRow(ctx, "SELECT cost_basis_method FROM portfolios WHERE id = $1 AND user_id = $2", portfolioID, userID).Scan(&method)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			h.sendErrorResponse(w, "Portfolio not found or access denied", http.StatusNotFound)
+			return
+		}
+		log.Printf("GetIncome - Failed to load portfolio %s for userID %s: %v", portfolioID, userID, err)
+		h.sendErrorResponse(w, "Failed to fetch portfolio", http.StatusInternalServerError)
+		return
+	}
+
+	stocks, ledgers, err := loadPortfolioLedgers(ctx, h.db, portfolioID)
+	if err != nil {
+		log.Printf("GetIncome - Failed to load ledger for portfolio %s: %v", portfolioID, err)
+		h.sendErrorResponse(w, "Failed to fetch transactions", http.StatusInternalServerError)
+		return
+	}
+
+	// A ledger that can't be replayed only costs that holding its yield on cost
+	costBasis := make(map[string]float64)
+	for _, stock := range stocks {
+		position, err := pnl.Calculate(stock.Ticker, ledgers[stock.ID], method)
+		if err != nil {
+			log.Printf("GetIncome - Failed to calculate %s in portfolio %s: %v", stock.Ticker, portfolioID, err)
+			continue
+		}
+		costBasis[strings.ToUpper(stock.Ticker)] += position.CostBasis
+	}
+
+	income, err := h.stockService.ProjectIncome(ctx, portfolioID, stocks, costBasis)
+	if err != nil {
+		log.Printf("GetIncome - Failed to project income for portfolio %s: %v", portfolioID, err)
+		h.sendErrorResponse(w, "Failed to project income", upstreamErrorStatus(err, http.StatusBadGateway))
+		return
+	}
+
+	response := models.APIResponse{Success: true, Data: income}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// loadPortfolioLedgers returns a portfolio's stocks and each stock's transactions
+// (keyed by stock ID, oldest first) in two queries.
+func loadPortfolioLedgers(ctx context.Context, q dbQuerier, portfolioID string) ([]models.Stock, map[string][]models.Transaction, error) {
+	rows, err := q.Query(ctx, `
+        SELECT id, portfolio_id, ticker, shares, created_at, updated_at
+        FROM stocks
+        WHERE portfolio_id = $1
+        ORDER BY created_at ASC
+    `, portfolioID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query stocks: %w", err)
+	}
+	stocks, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.Stock])
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to scan stocks: %w", err)
+	}
+
+	rows, err = q.Query(ctx, `
+        SELECT `+transactionColumns+`
+        FROM transactions t
+        JOIN stocks s ON t.stock_id = s.id
+        WHERE s.portfolio_id = $1
+        ORDER BY t.trade_date ASC, t.created_at ASC
+    `, portfolioID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query transactions: %w", err)
+	}
+	transactions, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.Transaction])
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to scan transactions: %w", err)
+	}
+
+	ledgers := make(map[string][]models.Transaction, len(stocks))
+	for _, t := range transactions {
+		ledgers[t.StockID] = append(ledgers[t.StockID], t)
+	}
+	return stocks, ledgers, nil
+}
+
+// sendErrorResponse is a helper to send consistent error responses
+func (h *PerformanceHandler) sendErrorResponse(w http.ResponseWriter, message string, statusCode int) {
+	response := models.ErrorResponse{
+		Success: false,
+		Error:   message,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		// If we can't encode the error response, fall back to plain text
+		http.Error(w, fmt.Sprintf("Error: %s", message), statusCode)
+	}
+}
@@ -5,9 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/cole-zoom/dUW-app/api/internal/clients"
+	"github.com/cole-zoom/dUW-app/api/internal/models"
 	"github.com/cole-zoom/dUW-app/api/internal/services"
 )
 
@@ -23,24 +28,46 @@ func NewStockAPIHandler(s *services.StockService) *StockAPIHandler {
 }
 
 // GetSuggestedStocks is the HTTP handler for the stock suggestions API endpoint.
+// GET /api/stocks/suggestions?query=apple&cursor={cursor}
+// When more results exist, the cursor for the next page is returned in the X-Next-Cursor header.
 func (h *StockAPIHandler) GetSuggestedStocks(w http.ResponseWriter, r *http.Request) {
-	query := r.URL.Query().Get("query") // Optional query parameter for searching
+	query := r.URL.Query().Get("query")   // Optional query parameter for searching
+	cursor := r.URL.Query().Get("cursor") // Optional cursor from a previous page
 
 	// Call the service layer to get the data
-	stocks, err := h.stockService.GetSuggestedStocks(r.Context(), query)
+	stocks, nextCursor, err := h.stockService.GetSuggestedStocksPage(r.Context(), query, cursor)
 	if err != nil {
 		log.Printf("Error getting suggested stocks: %v", err)
-		http.Error(w, fmt.Sprintf("Failed to get suggested stocks: %v", err), http.StatusInternalServerError)
+		sendUpstreamError(w, fmt.Sprintf("Failed to get suggested stocks: %v", err), err)
 		return
 	}
 
+	if nextCursor != "" {
+		w.Header().Set("X-Next-Cursor", nextCursor)
+	}
+
 	// Respond with JSON
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(stocks)
 }
 
+// maxAggregateSessions bounds the sessions parameter to about ten years of trading days
+const maxAggregateSessions = 2520
+
+// aggregateTimespans are the bar sizes the providers accept
+var aggregateTimespans = map[string]bool{
+	"second": true, "minute": true, "hour": true, "day": true,
+	"week": true, "month": true, "quarter": true, "year": true,
+}
+
 // GetAggregates is the HTTP handler for fetching historical OHLC data.
+// GET /api/stocks/{ticker}/aggregates?period=1M
+// GET /api/stocks/{ticker}/aggregates?timespan=minute&multiplier=5&sessions=1
 // GET /api/stocks/{ticker}/aggregates?timespan=day&from=2024-01-01&to=2024-12-31&multiplier=1
+// The range is a named period (1D, 1W, 1M, 6M, 1Y, YTD, 5Y, MAX), the last N trading
+// sessions, or explicit from/to dates. A period picks its own bar size, so it can't
+// be combined with timespan or multiplier: a fine bar over a long period would ask
+// the provider for an unbounded number of bars.
 func (h *StockAPIHandler) GetAggregates(w http.ResponseWriter, r *http.Request) {
 	ticker := r.PathValue("ticker")
 	if ticker == "" {
@@ -48,32 +75,89 @@ func (h *StockAPIHandler) GetAggregates(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Get query parameters with defaults
-	timespan := r.URL.Query().Get("timespan")
+	query := r.URL.Query()
+	timespan := query.Get("timespan")
+	multiplier := query.Get("multiplier")
+	from := query.Get("from")
+	to := query.Get("to")
+	period := query.Get("period")
+	rawSessions := query.Get("sessions")
+
+	if (period != "" && (rawSessions != "" || from != "" || to != "")) || (rawSessions != "" && (from != "" || to != "")) {
+		http.Error(w, "Use only one of 'period', 'sessions', or 'from' and 'to'", http.StatusBadRequest)
+		return
+	}
+	if period != "" && (timespan != "" || multiplier != "") {
+		http.Error(w, "'period' picks its own bar size; omit 'timespan' and 'multiplier'", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case period != "":
+		resolved, err := h.stockService.ResolvePeriod(r.Context(), period)
+		if errors.Is(err, services.ErrUnknownPeriod) {
+			http.Error(w, fmt.Sprintf("'period' must be one of %s", strings.Join(services.AggregatePeriods, ", ")), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			log.Printf("Error resolving period %s: %v", period, err)
+			http.Error(w, "Failed to resolve period", http.StatusInternalServerError)
+			return
+		}
+		from, to = resolved.From, resolved.To
+		timespan, multiplier = resolved.Timespan, resolved.Multiplier
+	case rawSessions != "":
+		sessions, err := strconv.Atoi(rawSessions)
+		if err != nil || sessions < 1 || sessions > maxAggregateSessions {
+			http.Error(w, fmt.Sprintf("'sessions' must be between 1 and %d", maxAggregateSessions), http.StatusBadRequest)
+			return
+		}
+		from, to = h.stockService.SessionRange(r.Context(), sessions)
+	}
+
+	// Defaults for explicit ranges
 	if timespan == "" {
 		timespan = "day"
 	}
-
-	multiplier := r.URL.Query().Get("multiplier")
 	if multiplier == "" {
 		multiplier = "1"
 	}
 
-	from := r.URL.Query().Get("from")
-	to := r.URL.Query().Get("to")
+	if !aggregateTimespans[timespan] {
+		http.Error(w, "'timespan' must be one of second, minute, hour, day, week, month, quarter, year", http.StatusBadRequest)
+		return
+	}
+	if n, err := strconv.Atoi(multiplier); err != nil || n < 1 {
+		http.Error(w, "'multiplier' must be a positive whole number", http.StatusBadRequest)
+		return
+	}
 
 	if from == "" || to == "" {
 		http.Error(w, "Both 'from' and 'to' date parameters are required (YYYY-MM-DD format)", http.StatusBadRequest)
 		return
 	}
+	fromDate, fromErr := time.Parse("2006-01-02", from)
+	toDate, toErr := time.Parse("2006-01-02", to)
+	if fromErr != nil || toErr != nil {
+		http.Error(w, "'from' and 'to' must be dates in YYYY-MM-DD format", http.StatusBadRequest)
+		return
+	}
+	if fromDate.After(toDate) {
+		http.Error(w, "'from' must not be after 'to'", http.StatusBadRequest)
+		return
+	}
 
 	aggregates, err := h.stockService.GetAggregates(r.Context(), ticker, multiplier, timespan, from, to)
 	if err != nil {
 		log.Printf("Error getting aggregates for %s: %v", ticker, err)
-		http.Error(w, fmt.Sprintf("Failed to get aggregates: %v", err), http.StatusInternalServerError)
+		sendUpstreamError(w, fmt.Sprintf("Failed to get aggregates: %v", err), err)
 		return
 	}
 
+	// Tell the caller what a period or session count resolved to
+	w.Header().Set("X-Range-From", from)
+	w.Header().Set("X-Range-To", to)
+	w.Header().Set("X-Range-Bar", multiplier+" "+timespan)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(aggregates)
 }
@@ -98,7 +182,7 @@ func (h *StockAPIHandler) GetTickerDetails(w http.ResponseWriter, r *http.Reques
 			return
 		}
 
-		http.Error(w, fmt.Sprintf("Failed to get ticker details: %v", err), http.StatusInternalServerError)
+		sendUpstreamError(w, fmt.Sprintf("Failed to get ticker details: %v", err), err)
 		return
 	}
 
@@ -118,10 +202,158 @@ func (h *StockAPIHandler) GetPreviousClose(w http.ResponseWriter, r *http.Reques
 	prevClose, err := h.stockService.GetPreviousClose(r.Context(), ticker)
 	if err != nil {
 		log.Printf("Error getting previous close for %s: %v", ticker, err)
-		http.Error(w, fmt.Sprintf("Failed to get previous close: %v", err), http.StatusInternalServerError)
+		sendUpstreamError(w, fmt.Sprintf("Failed to get previous close: %v", err), err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(prevClose)
 }
+
+// GetDividends is the HTTP handler for fetching a ticker's dividend history.
+// GET /api/stocks/{ticker}/dividends
+func (h *StockAPIHandler) GetDividends(w http.ResponseWriter, r *http.Request) {
+	ticker := r.PathValue("ticker")
+	if ticker == "" {
+		http.Error(w, "Ticker is required", http.StatusBadRequest)
+		return
+	}
+
+	dividends, err := h.stockService.GetDividends(r.Context(), ticker)
+	if err != nil {
+		log.Printf("Error getting dividends for %s: %v", ticker, err)
+		sendUpstreamError(w, fmt.Sprintf("Failed to get dividends: %v", err), err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dividends)
+}
+
+// GetSplits is the HTTP handler for fetching a ticker's stock split history.
+// GET /api/stocks/{ticker}/splits
+func (h *StockAPIHandler) GetSplits(w http.ResponseWriter, r *http.Request) {
+	ticker := r.PathValue("ticker")
+	if ticker == "" {
+		http.Error(w, "Ticker is required", http.StatusBadRequest)
+		return
+	}
+
+	splits, err := h.stockService.GetSplits(r.Context(), ticker)
+	if err != nil {
+		log.Printf("Error getting splits for %s: %v", ticker, err)
+		sendUpstreamError(w, fmt.Sprintf("Failed to get splits: %v", err), err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(splits)
+}
+
+// GetFinancials is the HTTP handler for a ticker's financial statements and ratios.
+// GET /api/stocks/{ticker}/financials?timeframe=quarterly
+func (h *StockAPIHandler) GetFinancials(w http.ResponseWriter, r *http.Request) {
+	ticker := r.PathValue("ticker")
+	if ticker == "" {
+		http.Error(w, "Ticker is required", http.StatusBadRequest)
+		return
+	}
+
+	timeframe := r.URL.Query().Get("timeframe")
+	if timeframe == "" {
+		timeframe = services.TimeframeQuarterly
+	}
+	if timeframe != services.TimeframeQuarterly && timeframe != services.TimeframeAnnual {
+		http.Error(w, "timeframe must be quarterly or annual", http.StatusBadRequest)
+		return
+	}
+
+	fundamentals, err := h.stockService.GetFundamentals(r.Context(), ticker, timeframe)
+	if err != nil {
+		log.Printf("Error getting financials for %s: %v", ticker, err)
+		sendUpstreamError(w, fmt.Sprintf("Failed to get financials: %v", err), err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(fundamentals)
+}
+
+// Paging limits for news feeds
+const (
+	defaultNewsLimit = 20
+	maxNewsLimit     = 100
+)
+
+// GetNews is the HTTP handler for a ticker's news feed, newest first.
+// GET /api/stocks/{ticker}/news?limit=20&cursor={cursor}
+func (h *StockAPIHandler) GetNews(w http.ResponseWriter, r *http.Request) {
+	ticker := r.PathValue("ticker")
+	if ticker == "" {
+		http.Error(w, "Ticker is required", http.StatusBadRequest)
+		return
+	}
+
+	limit, err := parseNewsLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	params := models.NewsParams{Ticker: ticker, Limit: limit, Cursor: r.URL.Query().Get("cursor")}
+	news, err := h.stockService.GetNews(r.Context(), params)
+	if err != nil {
+		log.Printf("Error getting news for %s: %v", ticker, err)
+		sendUpstreamError(w, fmt.Sprintf("Failed to get news: %v", err), err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(news)
+}
+
+// parseNewsLimit reads an optional page size for news feeds
+func parseNewsLimit(raw string) (int, error) {
+	if raw == "" {
+		return defaultNewsLimit, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 || limit > maxNewsLimit {
+		return 0, fmt.Errorf("limit must be between 1 and %d", maxNewsLimit)
+	}
+	return limit, nil
+}
+
+// sendUpstreamError writes a plain-text error with the status matching a market data failure.
+// Rate-limited and unavailable responses pass a Retry-After through when it is known.
+func sendUpstreamError(w http.ResponseWriter, message string, err error) {
+	status := upstreamErrorStatus(err, http.StatusInternalServerError)
+	retryable := status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable
+	if retryAfter := clients.RetryAfter(err); retryable && retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+	http.Error(w, message, status)
+}
+
+// upstreamErrorStatus maps market data errors onto the HTTP status we report:
+// 404 for unknown tickers, 429 when the provider is rate limiting us or our own
+// limiter can't fit the request in before its deadline, 503 when it is down, 501
+// when no configured provider offers the data, and 502 when it rejects our key,
+// our plan doesn't cover the request, or it answers unexpectedly.
+func upstreamErrorStatus(err error, fallback int) int {
+	var statusErr *clients.StatusError
+	switch {
+	case errors.Is(err, clients.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, clients.ErrRateLimited), errors.Is(err, clients.ErrAdmissionRejected):
+		return http.StatusTooManyRequests
+	case errors.Is(err, clients.ErrUpstreamUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, clients.ErrUnsupported):
+		return http.StatusNotImplemented
+	case errors.Is(err, clients.ErrUnauthorized), errors.Is(err, clients.ErrForbidden), errors.As(err, &statusErr):
+		return http.StatusBadGateway
+	default:
+		return fallback
+	}
+}
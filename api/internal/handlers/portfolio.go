@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,20 +9,24 @@ import (
 	"net/http"
 
 	"github.com/cole-zoom/dUW-app/api/internal/models"
+	"github.com/cole-zoom/dUW-app/api/internal/services"
+	"github.com/cole-zoom/dUW-app/api/internal/services/pnl"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// PortfolioHandler to hold db connection pool
+// PortfolioHandler to hold db connection pool and the stock service used for pricing
 type PortfolioHandler struct {
-	db *pgxpool.Pool
+	db           *pgxpool.Pool
+	stockService *services.StockService
 }
 
 // Creates a new portfolio handler with database connection pool
-func NewPortfolioHandler(db *pgxpool.Pool) *PortfolioHandler {
+func NewPortfolioHandler(db *pgxpool.Pool, s *services.StockService) *PortfolioHandler {
 	return &PortfolioHandler{
-		db: db,
+		db:           db,
+		stockService: s,
 	}
 }
 
@@ -38,64 +43,12 @@ func (h *PortfolioHandler) GetPortfolios(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	query := `
-        SELECT
-            p.id,
-            p.name,
-            p.user_id,
-            p.created_at,
-            p.updated_at,
-            COALESCE(
-                (SELECT json_agg(
-                    json_build_object(
-                        'id', s.id,
-                        'portfolio_id', s.portfolio_id,
-                        'ticker', s.ticker,
-                        'shares', s.shares,
-                        'created_at', to_char(s.created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"'),
-                        'updated_at', to_char(s.updated_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"')
-                    ) ORDER BY s.created_at ASC
-                 )
-                 FROM stocks s
-                 WHERE s.portfolio_id = p.id),
-                '[]'::json
-            ) AS stocks
-        FROM
-            portfolios p
-		WHERE
-			p.user_id = $1
-        ORDER BY
-            p.created_at ASC;
-    `
-	rows, err := h.db.Query(ctx, query, userID)
-
+	portfolios, err := h.fetchPortfolios(ctx, userID, "")
 	if err != nil {
-		log.Printf("GetPortfolios - Database query failed for userID %s: %v", userID, err)
+		log.Printf("GetPortfolios - %v", err)
 		h.sendErrorResponse(w, "Failed to fetch portfolios", http.StatusInternalServerError)
 		return
 	}
-	defer rows.Close()
-
-	// Parse results into portfolio structs
-	portfolios := make([]models.Portfolio, 0)
-
-	for rows.Next() {
-		var p models.Portfolio
-		var stocksJSON []byte
-
-		if err := rows.Scan(&p.ID, &p.Name, &p.UserID, &p.CreatedAt, &p.UpdatedAt, &stocksJSON); err != nil {
-			log.Printf("GetPortfolios - Failed to scan portfolio row for userID %s: %v", userID, err)
-			h.sendErrorResponse(w, "Failed to scan portfolio data", http.StatusInternalServerError)
-			return
-		}
-
-		if err := json.Unmarshal(stocksJSON, &p.Stocks); err != nil {
-			log.Printf("GetPortfolios - Failed to unmarshal stocks JSON for portfolio %s, userID %s: %v", p.ID, userID, err)
-			h.sendErrorResponse(w, "Failed to unmarshal stocks", http.StatusInternalServerError)
-			return
-		}
-		portfolios = append(portfolios, p)
-	}
 
 	response := models.APIResponse{
 		Success: true,
@@ -152,8 +105,8 @@ func (h *PortfolioHandler) CreatePortfolio(w http.ResponseWriter, r *http.Reques
 	err := h.db.QueryRow(ctx, `
 		INSERT INTO portfolios (name, user_id)
 		VALUES ($1, $2)
-		RETURNING id, name, user_id, created_at, updated_at
-	`, req.Name, userID).Scan(&portfolio.ID, &portfolio.Name, &portfolio.UserID, &portfolio.CreatedAt, &portfolio.UpdatedAt)
+		RETURNING id, name, user_id, cost_basis_method, created_at, updated_at
+	`, req.Name, userID).Scan(&portfolio.ID, &portfolio.Name, &portfolio.UserID, &portfolio.CostBasisMethod, &portfolio.CreatedAt, &portfolio.UpdatedAt)
 
 	if err != nil {
 		log.Printf("CreatePortfolio - Database insert failed for userID %s, portfolio name='%s': %v", userID, req.Name, err)
@@ -212,18 +165,26 @@ func (h *PortfolioHandler) UpdatePortfolio(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	if req.Name == "" {
+	if req.Name == "" && req.CostBasisMethod == nil {
 		h.sendErrorResponse(w, "Name is required", http.StatusBadRequest)
 		return
 	}
 
+	if req.CostBasisMethod != nil && !pnl.Method(*req.CostBasisMethod).Valid() {
+		h.sendErrorResponse(w, "cost_basis_method must be one of fifo, lifo, average_cost, specific_lot", http.StatusBadRequest)
+		return
+	}
+
 	var portfolio models.Portfolio
 
 	err := h.db.QueryRow(ctx, `
-		UPDATE portfolios SET name = $1, updated_at = CURRENT_TIMESTAMP 
-		WHERE id = $2 AND user_id = $3
-		RETURNING id, name, user_id, created_at, updated_at
-	`, req.Name, portfolioID, userID).Scan(&portfolio.ID, &portfolio.Name, &portfolio.UserID, &portfolio.CreatedAt, &portfolio.UpdatedAt)
+		UPDATE portfolios SET
+			name = COALESCE(NULLIF($1, ''), name),
+			cost_basis_method = COALESCE($2, cost_basis_method),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND user_id = $4
+		RETURNING id, name, user_id, cost_basis_method, created_at, updated_at
+	`, req.Name, req.CostBasisMethod, portfolioID, userID).Scan(&portfolio.ID, &portfolio.Name, &portfolio.UserID, &portfolio.CostBasisMethod, &portfolio.CreatedAt, &portfolio.UpdatedAt)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -309,6 +270,162 @@ func (h *PortfolioHandler) DeletePortfolio(w http.ResponseWriter, r *http.Reques
 	json.NewEncoder(w).Encode(response)
 }
 
+// GetValuation --> GET /api/portfolios/{id}/valuation
+// Prices every holding server-side in one pass instead of one request per ticker.
+func (h *PortfolioHandler) GetValuation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := ctx.Value("userID").(string)
+	if !ok {
+		h.sendErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	portfolioID := r.PathValue("id")
+
+	portfolios, err := h.fetchPortfolios(ctx, userID, portfolioID)
+	if err != nil {
+		log.Printf("GetValuation - %v", err)
+		h.sendErrorResponse(w, "Failed to fetch portfolio", http.StatusInternalServerError)
+		return
+	}
+	if len(portfolios) == 0 {
+		h.sendErrorResponse(w, "Portfolio not found or access denied", http.StatusNotFound)
+		return
+	}
+
+	valuation, err := h.stockService.ValueHoldings(ctx, portfolioID, portfolios[0].Stocks)
+	if err != nil {
+		log.Printf("GetValuation - Failed to value portfolio %s for userID %s: %v", portfolioID, userID, err)
+		h.sendErrorResponse(w, "Failed to value portfolio", upstreamErrorStatus(err, http.StatusBadGateway))
+		return
+	}
+
+	response := models.APIResponse{
+		Success: true,
+		Data:    valuation,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetNews --> GET /api/portfolios/{id}/news?limit=20&cursor={cursor}
+// Merges the latest news about every holding into one feed, newest first.
+func (h *PortfolioHandler) GetNews(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := ctx.Value("userID").(string)
+	if !ok {
+		h.sendErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	portfolioID := r.PathValue("id")
+
+	limit, err := parseNewsLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		h.sendErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	portfolios, err := h.fetchPortfolios(ctx, userID, portfolioID)
+	if err != nil {
+		log.Printf("GetNews - %v", err)
+		h.sendErrorResponse(w, "Failed to fetch portfolio", http.StatusInternalServerError)
+		return
+	}
+	if len(portfolios) == 0 {
+		h.sendErrorResponse(w, "Portfolio not found or access denied", http.StatusNotFound)
+		return
+	}
+
+	news, err := h.stockService.PortfolioNews(ctx, portfolioID, portfolios[0].Stocks, limit, r.URL.Query().Get("cursor"))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidNewsCursor) {
+			h.sendErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("GetNews - Failed to get news for portfolio %s, userID %s: %v", portfolioID, userID, err)
+		h.sendErrorResponse(w, "Failed to get news", upstreamErrorStatus(err, http.StatusBadGateway))
+		return
+	}
+
+	response := models.APIResponse{
+		Success: true,
+		Data:    news,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// fetchPortfolios loads a user's portfolios with their stocks embedded.
+// When portfolioID is non-empty only that portfolio is returned.
+func (h *PortfolioHandler) fetchPortfolios(ctx context.Context, userID, portfolioID string) ([]models.Portfolio, error) {
+	query := `
+        SELECT
+            p.id,
+            p.name,
+            p.user_id,
+            p.cost_basis_method,
+            p.created_at,
+            p.updated_at,
+            COALESCE(
+                (SELECT json_agg(
+                    json_build_object(
+                        'id', s.id,
+                        'portfolio_id', s.portfolio_id,
+                        'ticker', s.ticker,
+                        'shares', s.shares,
+                        'created_at', to_char(s.created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"'),
+                        'updated_at', to_char(s.updated_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"')
+                    ) ORDER BY s.created_at ASC
+                 )
+                 FROM stocks s
+                 WHERE s.portfolio_id = p.id),
+                '[]'::json
+            ) AS stocks
+        FROM
+            portfolios p
+		WHERE
+			p.user_id = $1
+			AND ($2 = '' OR p.id::text = $2)
+        ORDER BY
+            p.created_at ASC;
+    `
+	rows, err := h.db.Query(ctx, query, userID, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("database query failed for userID %s: %w", userID, err)
+	}
+	defer rows.Close()
+
+	// Parse results into portfolio structs
+	portfolios := make([]models.Portfolio, 0)
+
+	for rows.Next() {
+		var p models.Portfolio
+		var stocksJSON []byte
+
+		if err := rows.Scan(&p.ID, &p.Name, &p.UserID, &p.CostBasisMethod, &p.CreatedAt, &p.UpdatedAt, &stocksJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan portfolio row for userID %s: %w", userID, err)
+		}
+
+		if err := json.Unmarshal(stocksJSON, &p.Stocks); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal stocks JSON for portfolio %s, userID %s: %w", p.ID, userID, err)
+		}
+		portfolios = append(portfolios, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration failed for userID %s: %w", userID, err)
+	}
+
+	return portfolios, nil
+}
+
 // sendErrorResponse is a helper to send consistent error responses
 func (h *PortfolioHandler) sendErrorResponse(w http.ResponseWriter, message string, statusCode int) {
 	response := models.ErrorResponse{
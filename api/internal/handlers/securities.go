@@ -1,134 +1,143 @@
 package handlers
 
 import (
-	"compress/gzip"
-	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cole-zoom/dUW-app/api/internal/models"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/cole-zoom/dUW-app/api/internal/services"
 )
 
 // SecuritiesHandler handles all securities-related HTTP requests
 type SecuritiesHandler struct {
-	db *pgxpool.Pool
+	index *services.SecuritiesIndex
 }
 
-// NewSecuritiesHandler creates a new securities handler with database connection pool
-func NewSecuritiesHandler(db *pgxpool.Pool) *SecuritiesHandler {
+// NewSecuritiesHandler creates a new securities handler backed by the shared in-memory index
+func NewSecuritiesHandler(index *services.SecuritiesIndex) *SecuritiesHandler {
 	return &SecuritiesHandler{
-		db: db,
+		index: index,
 	}
 }
 
 // GetSecuritiesTrie --> GET /api/securities/trie
-// Returns all securities organized in a Trie data structure for efficient autocomplete
+// Returns all securities organized in a Trie data structure for efficient autocomplete.
+// The gzipped payload is built once per index refresh and revalidated with its ETag.
 func (h *SecuritiesHandler) GetSecuritiesTrie(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	startTime := time.Now()
-
-	log.Println("Building securities Trie...")
-
-	// Query all active securities from the database
-	securities, err := h.fetchAllSecurities(ctx)
+	snapshot, err := h.index.Get(r.Context())
 	if err != nil {
 		h.sendErrorResponse(w, "Failed to fetch securities", http.StatusInternalServerError)
 		return
 	}
 
-	if len(securities) == 0 {
+	if len(snapshot.Securities) == 0 {
 		h.sendErrorResponse(w, "No securities found", http.StatusNotFound)
 		return
 	}
 
-	// Build the Trie
-	trie := models.NewTrie()
-	for _, security := range securities {
-		trie.Insert(security)
-	}
-
-	buildTime := time.Since(startTime)
-	log.Printf("Built Trie with %d securities in %v", len(securities), buildTime)
+	w.Header().Set("ETag", snapshot.ETag)
+	w.Header().Set("Cache-Control", "public, max-age=3600") // Cache for 1 hour, then revalidate
+	w.Header().Set("Vary", "Accept-Encoding")
 
-	// Prepare the response
-	response := models.CompressedTrieResponse{
-		Trie:      trie,
-		Count:     len(securities),
-		Version:   "1.0",
-		BuildTime: buildTime.String(),
+	if etagMatches(r.Header.Get("If-None-Match"), snapshot.ETag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
 	}
 
-	// Set headers for compression and caching
+	// Set headers for the pre-compressed payload
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Content-Encoding", "gzip")
-	w.Header().Set("Cache-Control", "public, max-age=3600") // Cache for 1 hour
-	w.Header().Set("Vary", "Accept-Encoding")
-
-	// Create gzip writer
-	gz := gzip.NewWriter(w)
-	defer gz.Close()
+	w.Header().Set("Content-Length", strconv.Itoa(len(snapshot.TriePayload)))
+	w.WriteHeader(http.StatusOK)
 
-	// Encode and compress the response
-	if err := json.NewEncoder(gz).Encode(response); err != nil {
-		log.Printf("Failed to encode and compress response: %v", err)
-		h.sendErrorResponse(w, "Failed to compress response", http.StatusInternalServerError)
-		return
+	if _, err := w.Write(snapshot.TriePayload); err != nil {
+		log.Printf("Failed to write Trie response: %v", err)
 	}
-
-	log.Printf("Successfully served compressed Trie response")
 }
 
-// SearchSecurities --> GET /api/securities/search?q={prefix}
-// Returns securities that match the given prefix using the Trie
+// Paging limits for securities search
+const (
+	defaultSearchLimit = 50
+	maxSearchLimit     = 200
+)
+
+// SearchSecurities --> GET /api/securities/search?q={query}&type=CS,ETF&limit=50&cursor={cursor}
+// Returns securities matching the query by ticker, company name, or a close misspelling
+// of either, ranked with exact ticker matches first. Results can be filtered by type,
+// primary_exchange, market, locale, and currency_name (comma-separated values are ORed),
+// and are paged with limit/cursor over the full, stably ordered result set.
 func (h *SecuritiesHandler) SearchSecurities(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	query := r.URL.Query().Get("q")
+	params := r.URL.Query()
+	query := params.Get("q")
 
 	if query == "" {
 		h.sendErrorResponse(w, "Query parameter 'q' is required", http.StatusBadRequest)
 		return
 	}
 
-	if len(query) < 1 {
-		h.sendErrorResponse(w, "Query must be at least 1 character", http.StatusBadRequest)
-		return
+	limit := defaultSearchLimit
+	if raw := params.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxSearchLimit {
+			h.sendErrorResponse(w, fmt.Sprintf("limit must be between 1 and %d", maxSearchLimit), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	filter := models.SecurityFilter{
+		Type:            splitFilterValues(params.Get("type")),
+		PrimaryExchange: splitFilterValues(params.Get("primary_exchange")),
+		Market:          splitFilterValues(params.Get("market")),
+		Locale:          splitFilterValues(params.Get("locale")),
+		CurrencyName:    splitFilterValues(params.Get("currency_name")),
 	}
 
-	log.Printf("Searching securities with prefix: %s", query)
+	log.Printf("Searching securities for: %s", query)
 
-	// For now, we'll rebuild the Trie on each search. In production, you might want to cache this.
-	securities, err := h.fetchAllSecurities(ctx)
+	snapshot, err := h.index.Get(r.Context())
 	if err != nil {
 		h.sendErrorResponse(w, "Failed to fetch securities", http.StatusInternalServerError)
 		return
 	}
 
-	// Build the Trie
-	trie := models.NewTrie()
-	for _, security := range securities {
-		trie.Insert(security)
+	offset := 0
+	if cursor := params.Get("cursor"); cursor != "" {
+		offset, err = decodeSearchCursor(cursor, snapshot.ETag)
+		if err != nil {
+			h.sendErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 	}
 
-	// Search for matching securities
-	matches := trie.Search(query)
+	// Search for matching securities, best match first
+	matches := snapshot.Search(query, filter)
+	total := len(matches)
 
-	// Limit results to prevent overwhelming the client
-	maxResults := 50
-	if len(matches) > maxResults {
-		matches = matches[:maxResults]
+	page := matches[min(offset, total):min(offset+limit, total)]
+	var nextCursor *string
+	if offset+limit < total {
+		cursor := encodeSearchCursor(offset+limit, snapshot.ETag)
+		nextCursor = &cursor
 	}
 
 	response := models.APIResponse{
 		Success: true,
 		Data: map[string]interface{}{
-			"query":   query,
-			"matches": matches,
-			"count":   len(matches),
-			"limited": len(matches) == maxResults,
+			"query":       query,
+			"filters":     filter,
+			"matches":     page,
+			"count":       len(page),
+			"total":       total,
+			"limited":     nextCursor != nil,
+			"next_cursor": nextCursor,
 		},
 	}
 
@@ -137,54 +146,77 @@ func (h *SecuritiesHandler) SearchSecurities(w http.ResponseWriter, r *http.Requ
 	json.NewEncoder(w).Encode(response)
 }
 
-// fetchAllSecurities retrieves all securities from the database
-func (h *SecuritiesHandler) fetchAllSecurities(ctx context.Context) ([]models.Securities, error) {
-	query := `
-		SELECT ticker, name, market, locale, primary_exchange, type, active, 
-		       currency_name, cik, composite_figi, share_class_figi, 
-		       to_char(last_updated_utc AT TIME ZONE 'UTC', 'YYYY-MM-DD HH24:MI:SS.MS+00') as last_updated_utc
-		FROM securities 
-		WHERE active = true 
-		ORDER BY ticker ASC
-	`
-
-	rows, err := h.db.Query(ctx, query)
+// RefreshSecurities --> POST /api/admin/securities/refresh
+// Rebuilds the in-memory securities index immediately, e.g. after a securities sync.
+func (h *SecuritiesHandler) RefreshSecurities(w http.ResponseWriter, r *http.Request) {
+	snapshot, err := h.index.Refresh(r.Context())
 	if err != nil {
-		log.Printf("Database query failed: %v", err)
-		return nil, fmt.Errorf("failed to query securities: %w", err)
-	}
-	defer rows.Close()
-
-	var securities []models.Securities
-	for rows.Next() {
-		var security models.Securities
-		err := rows.Scan(
-			&security.Ticker,
-			&security.Name,
-			&security.Market,
-			&security.Locale,
-			&security.PrimaryExchange,
-			&security.Type,
-			&security.Active,
-			&security.CurrencyName,
-			&security.Cik,
-			&security.CompositeFigi,
-			&security.ShareClassFigi,
-			&security.LastUpdatedUtc,
-		)
-		if err != nil {
-			log.Printf("Failed to scan security row: %v", err)
-			return nil, fmt.Errorf("failed to scan security: %w", err)
+		log.Printf("RefreshSecurities - Failed to rebuild index: %v", err)
+		h.sendErrorResponse(w, "Failed to refresh securities", http.StatusInternalServerError)
+		return
+	}
+
+	response := models.APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"count":      len(snapshot.Securities),
+			"etag":       snapshot.ETag,
+			"built_at":   snapshot.BuiltAt.Format(time.RFC3339),
+			"build_time": snapshot.BuildTime.String(),
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// splitFilterValues turns "CS, ETF" into ["CS", "ETF"], dropping empty entries
+func splitFilterValues(raw string) []string {
+	var values []string
+	for _, value := range strings.Split(raw, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
 		}
-		securities = append(securities, security)
 	}
+	return values
+}
+
+// encodeSearchCursor makes an opaque cursor for the result offset within one index snapshot
+func encodeSearchCursor(offset int, etag string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%s", offset, etag)))
+}
 
-	if err := rows.Err(); err != nil {
-		log.Printf("Row iteration error: %v", err)
-		return nil, fmt.Errorf("row iteration failed: %w", err)
+// decodeSearchCursor returns the offset in a cursor, rejecting cursors issued
+// against a different snapshot since the result order may have changed.
+func decodeSearchCursor(cursor, etag string) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, errors.New("invalid cursor")
+	}
+	rawOffset, cursorETag, ok := strings.Cut(string(data), ":")
+	offset, err := strconv.Atoi(rawOffset)
+	if !ok || err != nil || offset < 0 {
+		return 0, errors.New("invalid cursor")
 	}
+	if cursorETag != etag {
+		return 0, errors.New("cursor has expired because securities were refreshed; restart the search")
+	}
+	return offset, nil
+}
 
-	return securities, nil
+// etagMatches reports whether an If-None-Match header matches the given ETag
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
 }
 
 // sendErrorResponse is a helper to send consistent error responses
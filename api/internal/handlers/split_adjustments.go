@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/cole-zoom/dUW-app/api/internal/jobs"
+	"github.com/cole-zoom/dUW-app/api/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SplitAdjustmentHandler lets users review the stock splits the split adjustment
+// job found for their positions, and apply or dismiss the ones it flagged.
+type SplitAdjustmentHandler struct {
+	db          *pgxpool.Pool
+	adjustments *jobs.SplitAdjustments
+}
+
+// NewSplitAdjustmentHandler creates a new split adjustment handler
+func NewSplitAdjustmentHandler(db *pgxpool.Pool, adjustments *jobs.SplitAdjustments) *SplitAdjustmentHandler {
+	return &SplitAdjustmentHandler{
+		db:          db,
+		adjustments: adjustments,
+	}
+}
+
+// GetSplitAdjustments --> GET /api/portfolios/{portfolioID}/split-adjustments?status={pending|applied|dismissed|recorded}
+func (h *SplitAdjustmentHandler) GetSplitAdjustments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, ok := ctx.Value("userID").(string)
+	if !ok {
+		h.sendErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	portfolioID := r.PathValue("portfolioID")
+	status := r.URL.Query().Get("status") // Optional filter
+	switch status {
+	case "", models.SplitAdjustmentPending, models.SplitAdjustmentApplied, models.SplitAdjustmentDismissed, models.SplitAdjustmentRecorded:
+	default:
+		h.sendErrorResponse(w, "status must be one of pending, applied, dismissed, recorded", http.StatusBadRequest)
+		return
+	}
+
+	adjustments, err := h.adjustments.List(ctx, userID, portfolioID, status)
+	if err != nil {
+		log.Printf("GetSplitAdjustments - Failed for portfolioID %s, userID %s: %v", portfolioID, userID, err)
+		h.sendErrorResponse(w, "Failed to fetch split adjustments", http.StatusInternalServerError)
+		return
+	}
+
+	if len(adjustments) == 0 {
+		var exists bool
+		err := h.db.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM portfolios WHERE id = $1 AND user_id = $2)", portfolioID, userID).Scan(&exists)
+		if err != nil {
+			h.sendErrorResponse(w, "Failed to verify portfolio", http.StatusInternalServerError)
+			return
+		}
+		if !exists {
+			h.sendErrorResponse(w, "Portfolio not found or access denied", http.StatusNotFound)
+			return
+		}
+	}
+
+	response := models.APIResponse{Success: true, Data: adjustments}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// ApplySplitAdjustment --> POST /api/portfolios/{portfolioID}/split-adjustments/{adjustmentID}/apply
+// Records the split in the position's ledger and re-derives its share count.
+func (h *SplitAdjustmentHandler) ApplySplitAdjustment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, ok := ctx.Value("userID").(string)
+	if !ok {
+		h.sendErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	portfolioID := r.PathValue("portfolioID")
+	adjustmentID := r.PathValue("adjustmentID")
+
+	adjustment, err := h.adjustments.Apply(ctx, userID, portfolioID, adjustmentID)
+	if err != nil {
+		if errors.Is(err, models.ErrOversold) {
+			h.sendErrorResponse(w, "Applying this split would leave a later sell without enough shares", http.StatusBadRequest)
+			return
+		}
+		h.sendAdjustmentError(w, "ApplySplitAdjustment", adjustmentID, err)
+		return
+	}
+
+	log.Printf("ApplySplitAdjustment - Applied %s split of %s for portfolioID=%s, userID=%s", adjustment.ExecutionDate.Format("2006-01-02"), adjustment.Ticker, portfolioID, userID)
+
+	response := models.APIResponse{Success: true, Data: adjustment}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// DismissSplitAdjustment --> POST /api/portfolios/{portfolioID}/split-adjustments/{adjustmentID}/dismiss
+// For positions whose ledger already reflects the split; nothing is changed.
+func (h *SplitAdjustmentHandler) DismissSplitAdjustment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, ok := ctx.Value("userID").(string)
+	if !ok {
+		h.sendErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	portfolioID := r.PathValue("portfolioID")
+	adjustmentID := r.PathValue("adjustmentID")
+
+	adjustment, err := h.adjustments.Dismiss(ctx, userID, portfolioID, adjustmentID)
+	if err != nil {
+		h.sendAdjustmentError(w, "DismissSplitAdjustment", adjustmentID, err)
+		return
+	}
+
+	response := models.APIResponse{Success: true, Data: adjustment}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// sendAdjustmentError maps errors shared by apply and dismiss onto responses
+func (h *SplitAdjustmentHandler) sendAdjustmentError(w http.ResponseWriter, caller, adjustmentID string, err error) {
+	switch {
+	case errors.Is(err, jobs.ErrAdjustmentNotFound):
+		h.sendErrorResponse(w, "Split adjustment not found or access denied", http.StatusNotFound)
+	case errors.Is(err, jobs.ErrAdjustmentResolved):
+		h.sendErrorResponse(w, "Split adjustment has already been resolved", http.StatusConflict)
+	default:
+		log.Printf("%s - Failed for adjustment %s: %v", caller, adjustmentID, err)
+		h.sendErrorResponse(w, "Failed to update split adjustment", http.StatusInternalServerError)
+	}
+}
+
+// sendErrorResponse is a helper to send consistent error responses
+func (h *SplitAdjustmentHandler) sendErrorResponse(w http.ResponseWriter, message string, statusCode int) {
+	response := models.ErrorResponse{
+		Success: false,
+		Error:   message,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		// If we can't encode the error response, fall back to plain text
+		http.Error(w, fmt.Sprintf("Error: %s", message), statusCode)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/cole-zoom/dUW-app/api/internal/models"
 	"github.com/jackc/pgx/v5"
@@ -13,6 +14,9 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// manualAdjustmentNote labels ledger entries created by editing a position's shares directly
+const manualAdjustmentNote = "Manual share adjustment"
+
 // StockHandler to hold db connection pool
 type StockHandler struct {
 	db *pgxpool.Pool
@@ -128,7 +132,15 @@ func (h *StockHandler) CreateStock(w http.ResponseWriter, r *http.Request) {
     `
 	log.Printf("CreateStock - Attempting to insert stock ticker='%s', shares=%f for portfolioID='%s', userID='%s'", req.Ticker, req.Shares, portfolioID, userID)
 
-	err := h.db.QueryRow(ctx, query, portfolioID, userID, req.Ticker, req.Shares).Scan(
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		log.Printf("CreateStock - Failed to begin transaction for userID %s: %v", userID, err)
+		h.sendErrorResponse(w, "Failed to create stock", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, query, portfolioID, userID, req.Ticker, req.Shares).Scan(
 		&stock.ID, &stock.PortfolioID, &stock.Ticker, &stock.Shares, &stock.CreatedAt, &stock.UpdatedAt,
 	)
 
@@ -150,6 +162,25 @@ func (h *StockHandler) CreateStock(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Record the opening buy so the position's shares are backed by the ledger
+	opening := models.Transaction{
+		Type:      models.TransactionBuy,
+		Shares:    req.Shares,
+		Price:     req.Price,
+		TradeDate: stock.CreatedAt,
+	}
+	if _, err := insertTransaction(ctx, tx, stock.ID, opening); err != nil {
+		log.Printf("CreateStock - Failed to record opening transaction for stockID %s: %v", stock.ID, err)
+		h.sendErrorResponse(w, "Failed to create stock", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("CreateStock - Commit failed for userID %s, portfolioID %s: %v", userID, portfolioID, err)
+		h.sendErrorResponse(w, "Failed to create stock", http.StatusInternalServerError)
+		return
+	}
+
 	log.Printf("CreateStock - Successfully created stock ID=%s for portfolioID=%s, userID=%s", stock.ID, portfolioID, userID)
 
 	response := models.APIResponse{Success: true, Data: stock}
@@ -185,20 +216,32 @@ func (h *StockHandler) UpdateStock(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Price != nil && *req.Price < 0 {
+		h.sendErrorResponse(w, "Price cannot be negative", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		h.sendErrorResponse(w, "Failed to update stock", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(ctx)
+
 	var stock models.Stock
 	// This single query updates the stock ONLY if it exists AND belongs to a portfolio
-	// owned by the authenticated user.
+	// owned by the authenticated user. Shares are never written directly; they are
+	// derived from the transaction ledger below.
 	query := `
         UPDATE stocks s SET
-            ticker = COALESCE($1, s.ticker),
-            shares = COALESCE($2, s.shares)
+            ticker = COALESCE($1, s.ticker)
         FROM portfolios p
-        WHERE s.id = $3
+        WHERE s.id = $2
           AND s.portfolio_id = p.id
-          AND p.user_id = $4
+          AND p.user_id = $3
         RETURNING s.id, s.portfolio_id, s.ticker, s.shares, s.created_at, s.updated_at
     `
-	err := h.db.QueryRow(ctx, query, req.Ticker, req.Shares, stockID, userID).Scan(
+	err = tx.QueryRow(ctx, query, req.Ticker, stockID, userID).Scan(
 		&stock.ID, &stock.PortfolioID, &stock.Ticker, &stock.Shares, &stock.CreatedAt, &stock.UpdatedAt,
 	)
 
@@ -211,6 +254,42 @@ func (h *StockHandler) UpdateStock(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A direct share edit is recorded as an adjusting buy or sell for the difference
+	if req.Shares != nil && *req.Shares != stock.Shares {
+		note := manualAdjustmentNote
+		adjustment := models.Transaction{
+			Type:      models.TransactionBuy,
+			Shares:    *req.Shares - stock.Shares,
+			TradeDate: time.Now().UTC(),
+			Notes:     &note,
+		}
+		if adjustment.Shares < 0 {
+			adjustment.Type = models.TransactionSell
+			adjustment.Shares = -adjustment.Shares
+		}
+		if req.Price != nil {
+			adjustment.Price = *req.Price
+		}
+
+		if _, err := insertTransaction(ctx, tx, stock.ID, adjustment); err != nil {
+			log.Printf("UpdateStock - Failed to record adjustment for stockID %s: %v", stock.ID, err)
+			h.sendErrorResponse(w, "Failed to update stock", http.StatusInternalServerError)
+			return
+		}
+
+		stock, err = syncStockShares(ctx, tx, stock.ID)
+		if err != nil {
+			log.Printf("UpdateStock - Failed to sync shares for stockID %s: %v", stockID, err)
+			h.sendErrorResponse(w, "Failed to update stock", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		h.sendErrorResponse(w, "Failed to update stock", http.StatusInternalServerError)
+		return
+	}
+
 	response := models.APIResponse{Success: true, Data: stock}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -0,0 +1,439 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cole-zoom/dUW-app/api/internal/models"
+	"github.com/cole-zoom/dUW-app/api/internal/services/pnl"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// dbQuerier is satisfied by both *pgxpool.Pool and pgx.Tx so ledger helpers
+// can run inside or outside a database transaction.
+type dbQuerier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// transactionColumns is the select list shared by every ledger query.
+// It expects transactions aliased as t and stocks aliased as s.
+const transactionColumns = `
+	t.id, t.stock_id, s.ticker, t.type, t.shares, t.price, t.fees,
+	t.split_ratio, t.trade_date, t.notes, t.lot_selections, t.created_at
+`
+
+// TransactionHandler to hold db connection pool
+type TransactionHandler struct {
+	db *pgxpool.Pool
+}
+
+// Creates a new transaction handler with database connection pool
+func NewTransactionHandler(db *pgxpool.Pool) *TransactionHandler {
+	return &TransactionHandler{
+		db: db,
+	}
+}
+
+// GetTransactions --> GET /api/portfolios/{portfolioID}/transactions?ticker={ticker}
+func (h *TransactionHandler) GetTransactions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, ok := ctx.Value("userID").(string)
+	if !ok {
+		h.sendErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	portfolioID := r.PathValue("portfolioID")
+	ticker := r.URL.Query().Get("ticker") // Optional filter
+
+	query := `
+        SELECT ` + transactionColumns + `
+        FROM transactions t
+        JOIN stocks s ON t.stock_id = s.id
+        JOIN portfolios p ON s.portfolio_id = p.id
+        WHERE s.portfolio_id = $1
+          AND p.user_id = $2
+          AND ($3 = '' OR UPPER(s.ticker) = UPPER($3))
+        ORDER BY t.trade_date ASC, t.created_at ASC;
+    `
+	rows, err := h.db.Query(ctx, query, portfolioID, userID, ticker)
+	if err != nil {
+		log.Printf("GetTransactions - Database query failed for portfolioID %s, userID %s: %v", portfolioID, userID, err)
+		h.sendErrorResponse(w, "Failed to fetch transactions", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	transactions, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.Transaction])
+	if err != nil {
+		log.Printf("GetTransactions - Failed to scan transactions for portfolioID %s, userID %s: %v", portfolioID, userID, err)
+		h.sendErrorResponse(w, "Failed to scan transaction data", http.StatusInternalServerError)
+		return
+	}
+
+	if len(transactions) == 0 {
+		var exists bool
+		err := h.db.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM portfolios WHERE id = $1 AND user_id = $2)", portfolioID, userID).Scan(&exists)
+		if err != nil {
+			h.sendErrorResponse(w, "Failed to verify portfolio", http.StatusInternalServerError)
+			return
+		}
+		if !exists {
+			h.sendErrorResponse(w, "Portfolio not found or access denied", http.StatusNotFound)
+			return
+		}
+	}
+
+	response := models.APIResponse{Success: true, Data: transactions}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// CreateTransaction --> POST /api/portfolios/{portfolioID}/transactions
+// Records a trade against the portfolio's position in the ticker, opening the
+// position on the first buy, and re-derives the position's share count.
+func (h *TransactionHandler) CreateTransaction(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, ok := ctx.Value("userID").(string)
+
+	log.Printf("CreateTransaction - Starting request for userID: %s", userID)
+	if !ok {
+		h.sendErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	portfolioID := r.PathValue("portfolioID")
+
+	var req models.CreateTransactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("CreateTransaction - Invalid JSON body for userID %s: %v", userID, err)
+		h.sendErrorResponse(w, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	entry, err := validateTransactionRequest(req)
+	if err != nil {
+		h.sendErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		log.Printf("CreateTransaction - Failed to begin transaction for userID %s: %v", userID, err)
+		h.sendErrorResponse(w, "Failed to create transaction", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	err = tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM portfolios WHERE id = $1 AND user_id = $2)", portfolioID, userID).Scan(&exists)
+	if err != nil {
+		h.sendErrorResponse(w, "Failed to verify portfolio", http.StatusInternalServerError)
+		return
+	}
+	if !exists {
+		h.sendErrorResponse(w, "Portfolio not found or access denied", http.StatusNotFound)
+		return
+	}
+
+	// Find the existing position for this ticker, locking it so concurrent
+	// trades on the same position are applied one at a time.
+	var stockID string
+	err = tx.QueryRow(ctx, `
+        SELECT id FROM stocks
+        WHERE portfolio_id = $1 AND UPPER(ticker) = UPPER($2)
+        ORDER BY created_at ASC
+        LIMIT 1
+        FOR UPDATE
+    `, portfolioID, entry.Ticker).Scan(&stockID)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		if entry.Type != models.TransactionBuy && entry.Type != models.TransactionDividendReinvest {
+			h.sendErrorResponse(w, fmt.Sprintf("No position in %s to apply a %s to", entry.Ticker, entry.Type), http.StatusBadRequest)
+			return
+		}
+
+		// Open a new position; its share count is filled in from the ledger below
+		err = tx.QueryRow(ctx, `
+            INSERT INTO stocks (portfolio_id, ticker, shares)
+            VALUES ($1, $2, 0)
+            RETURNING id
+        `, portfolioID, entry.Ticker).Scan(&stockID)
+	}
+	if err != nil {
+		log.Printf("CreateTransaction - Failed to resolve position for portfolioID %s, ticker='%s': %v", portfolioID, entry.Ticker, err)
+		h.sendErrorResponse(w, "Failed to create transaction", http.StatusInternalServerError)
+		return
+	}
+
+	transaction, err := insertTransaction(ctx, tx, stockID, entry)
+	if err != nil {
+		log.Printf("CreateTransaction - Database insert failed for stockID %s: %v", stockID, err)
+		h.sendErrorResponse(w, "Failed to create transaction", http.StatusInternalServerError)
+		return
+	}
+
+	stock, err := syncStockShares(ctx, tx, stockID)
+	if err != nil {
+		if errors.Is(err, models.ErrOversold) {
+			h.sendErrorResponse(w, "Sell exceeds the shares held on that date", http.StatusBadRequest)
+			return
+		}
+		log.Printf("CreateTransaction - Failed to sync shares for stockID %s: %v", stockID, err)
+		h.sendErrorResponse(w, "Failed to create transaction", http.StatusInternalServerError)
+		return
+	}
+
+	// Lot selections can only be checked against the replayed ledger
+	if len(entry.LotSelections) > 0 {
+		ledger, err := loadStockTransactions(ctx, tx, stockID)
+		if err != nil {
+			h.sendErrorResponse(w, "Failed to create transaction", http.StatusInternalServerError)
+			return
+		}
+		if _, err := pnl.Calculate(stock.Ticker, ledger, pnl.SpecificLot); err != nil {
+			h.sendErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("CreateTransaction - Commit failed for stockID %s: %v", stockID, err)
+		h.sendErrorResponse(w, "Failed to create transaction", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("CreateTransaction - Recorded %s of %f %s for portfolioID=%s, userID=%s", transaction.Type, transaction.Shares, stock.Ticker, portfolioID, userID)
+
+	response := models.APIResponse{
+		Success: true,
+		Data:    models.TransactionResponse{Transaction: transaction, Stock: stock},
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// DeleteTransaction --> DELETE /api/portfolios/{portfolioID}/transactions/{transactionID}
+func (h *TransactionHandler) DeleteTransaction(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, ok := ctx.Value("userID").(string)
+	if !ok {
+		h.sendErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	portfolioID := r.PathValue("portfolioID")
+	transactionID := r.PathValue("transactionID")
+
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		h.sendErrorResponse(w, "Failed to delete transaction", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// Delete the transaction ONLY if it belongs to a portfolio owned by the authenticated user
+	var stockID string
+	err = tx.QueryRow(ctx, `
+        DELETE FROM transactions t
+        USING stocks s, portfolios p
+        WHERE t.id = $1
+          AND t.stock_id = s.id
+          AND s.portfolio_id = $2
+          AND s.portfolio_id = p.id
+          AND p.user_id = $3
+        RETURNING t.stock_id
+    `, transactionID, portfolioID, userID).Scan(&stockID)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			h.sendErrorResponse(w, "Transaction not found or access denied", http.StatusNotFound)
+			return
+		}
+		h.sendErrorResponse(w, "Failed to delete transaction", http.StatusInternalServerError)
+		return
+	}
+
+	stock, err := syncStockShares(ctx, tx, stockID)
+	if err != nil {
+		if errors.Is(err, models.ErrOversold) {
+			h.sendErrorResponse(w, "Removing this transaction would leave a later sell without enough shares", http.StatusBadRequest)
+			return
+		}
+		log.Printf("DeleteTransaction - Failed to sync shares for stockID %s: %v", stockID, err)
+		h.sendErrorResponse(w, "Failed to delete transaction", http.StatusInternalServerError)
+		return
+	}
+
+	// A later sell may have chosen the deleted lot, or more shares than are left in a lot
+	ledger, err := loadStockTransactions(ctx, tx, stockID)
+	if err != nil {
+		h.sendErrorResponse(w, "Failed to delete transaction", http.StatusInternalServerError)
+		return
+	}
+	if slices.ContainsFunc(ledger, func(t models.Transaction) bool { return len(t.LotSelections) > 0 }) {
+		if _, err := pnl.Calculate(stock.Ticker, ledger, pnl.SpecificLot); err != nil {
+			h.sendErrorResponse(w, "Removing this transaction would invalidate the lots chosen by a later sell: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		h.sendErrorResponse(w, "Failed to delete transaction", http.StatusInternalServerError)
+		return
+	}
+
+	response := models.APIResponse{Success: true, Data: stock}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// validateTransactionRequest checks a request and converts it into a ledger entry
+func validateTransactionRequest(req models.CreateTransactionRequest) (models.Transaction, error) {
+	req.Ticker = strings.TrimSpace(req.Ticker)
+	if req.Ticker == "" {
+		return models.Transaction{}, errors.New("Ticker is required")
+	}
+	if !req.Type.Valid() {
+		return models.Transaction{}, fmt.Errorf("Invalid transaction type '%s'", req.Type)
+	}
+
+	tradeDate, err := time.Parse("2006-01-02", req.TradeDate)
+	if err != nil {
+		return models.Transaction{}, errors.New("trade_date must be in YYYY-MM-DD format")
+	}
+
+	if req.Price < 0 || req.Fees < 0 {
+		return models.Transaction{}, errors.New("Price and fees cannot be negative")
+	}
+
+	if req.Type == models.TransactionSplit {
+		if req.SplitRatio == nil || *req.SplitRatio <= 0 {
+			return models.Transaction{}, errors.New("A positive split_ratio is required for splits")
+		}
+		// Splits only rescale the position; they carry no quantity or price of their own
+		req.Shares, req.Price = 0, 0
+	} else {
+		if req.Shares <= 0 {
+			return models.Transaction{}, errors.New("Shares must be positive")
+		}
+		req.SplitRatio = nil
+	}
+
+	if len(req.LotSelections) > 0 && req.Type != models.TransactionSell {
+		return models.Transaction{}, errors.New("lot_selections only apply to sells")
+	}
+	for _, sel := range req.LotSelections {
+		if sel.TransactionID == "" || sel.Shares <= 0 {
+			return models.Transaction{}, errors.New("Each lot selection needs a transaction_id and positive shares")
+		}
+	}
+
+	return models.Transaction{
+		Ticker:        req.Ticker,
+		Type:          req.Type,
+		Shares:        req.Shares,
+		Price:         req.Price,
+		Fees:          req.Fees,
+		SplitRatio:    req.SplitRatio,
+		TradeDate:     tradeDate,
+		Notes:         req.Notes,
+		LotSelections: req.LotSelections,
+	}, nil
+}
+
+// insertTransaction writes a ledger entry for a stock and returns the stored row
+func insertTransaction(ctx context.Context, q dbQuerier, stockID string, entry models.Transaction) (models.Transaction, error) {
+	// Store SQL NULL rather than a JSON null when no lots were selected
+	var lotSelections any
+	if len(entry.LotSelections) > 0 {
+		lotSelections = entry.LotSelections
+	}
+
+	rows, err := q.Query(ctx, `
+        WITH t AS (
+            INSERT INTO transactions (stock_id, type, shares, price, fees, split_ratio, trade_date, notes, lot_selections)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+            RETURNING *
+        )
+        SELECT `+transactionColumns+`
+        FROM t
+        JOIN stocks s ON t.stock_id = s.id
+    `, stockID, entry.Type, entry.Shares, entry.Price, entry.Fees, entry.SplitRatio, entry.TradeDate, entry.Notes, lotSelections)
+	if err != nil {
+		return models.Transaction{}, fmt.Errorf("failed to insert transaction: %w", err)
+	}
+	return pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[models.Transaction])
+}
+
+// loadStockTransactions returns a stock's ledger ordered oldest first
+func loadStockTransactions(ctx context.Context, q dbQuerier, stockID string) ([]models.Transaction, error) {
+	rows, err := q.Query(ctx, `
+        SELECT `+transactionColumns+`
+        FROM transactions t
+        JOIN stocks s ON t.stock_id = s.id
+        WHERE t.stock_id = $1
+        ORDER BY t.trade_date ASC, t.created_at ASC
+    `, stockID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transactions: %w", err)
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.Transaction])
+}
+
+// syncStockShares re-derives a stock's share count from its ledger and stores it
+// on the stock row. Returns models.ErrOversold if the ledger is inconsistent.
+func syncStockShares(ctx context.Context, q dbQuerier, stockID string) (models.Stock, error) {
+	transactions, err := loadStockTransactions(ctx, q, stockID)
+	if err != nil {
+		return models.Stock{}, err
+	}
+
+	shares, err := models.DeriveShares(transactions)
+	if err != nil {
+		return models.Stock{}, err
+	}
+
+	var stock models.Stock
+	err = q.QueryRow(ctx, `
+        UPDATE stocks SET shares = $1, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $2
+        RETURNING id, portfolio_id, ticker, shares, created_at, updated_at
+    `, shares, stockID).Scan(
+		&stock.ID, &stock.PortfolioID, &stock.Ticker, &stock.Shares, &stock.CreatedAt, &stock.UpdatedAt,
+	)
+	if err != nil {
+		return models.Stock{}, fmt.Errorf("failed to update derived shares: %w", err)
+	}
+	return stock, nil
+}
+
+// sendErrorResponse is a helper to send consistent error responses
+func (h *TransactionHandler) sendErrorResponse(w http.ResponseWriter, message string, statusCode int) {
+	response := models.ErrorResponse{
+		Success: false,
+		Error:   message,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		// If we can't encode the error response, fall back to plain text
+		http.Error(w, fmt.Sprintf("Error: %s", message), statusCode)
+	}
+}
@@ -0,0 +1,151 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cole-zoom/dUW-app/api/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresStore keeps price history in the price_bars and price_bar_ranges tables
+type PostgresStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresStore creates a store backed by the given connection pool
+func NewPostgresStore(db *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Ranges(ctx context.Context, series Series) ([]Range, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT to_char(from_date, 'YYYY-MM-DD'), to_char(to_date, 'YYYY-MM-DD'), verified_at
+		FROM price_bar_ranges
+		WHERE ticker = $1 AND timespan = $2 AND multiplier = $3
+		ORDER BY from_date ASC
+	`, series.Ticker, series.Timespan, series.Multiplier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price ranges: %w", err)
+	}
+	ranges, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (Range, error) {
+		var r Range
+		err := row.Scan(&r.From, &r.To, &r.VerifiedAt)
+		return r, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan price ranges: %w", err)
+	}
+	return ranges, nil
+}
+
+func (s *PostgresStore) Bars(ctx context.Context, series Series, start, end time.Time) ([]models.AggregateBar, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT timestamp, open, high, low, close, volume, vwap, trades
+		FROM price_bars
+		WHERE ticker = $1 AND timespan = $2 AND multiplier = $3
+			AND timestamp >= $4 AND timestamp < $5
+		ORDER BY timestamp ASC
+	`, series.Ticker, series.Timespan, series.Multiplier, start.UnixMilli(), end.UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price bars: %w", err)
+	}
+	bars, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.AggregateBar, error) {
+		var bar models.AggregateBar
+		var timestamp int64
+		err := row.Scan(&timestamp, &bar.Open, &bar.High, &bar.Low, &bar.Close, &bar.Volume, &bar.VWAP, &bar.NumTrades)
+		bar.Timestamp = models.FlexibleInt64(timestamp)
+		return bar, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan price bars: %w", err)
+	}
+	return bars, nil
+}
+
+func (s *PostgresStore) Save(ctx context.Context, series Series, from, to string, bars []models.AggregateBar) error {
+	// Columns as arrays so the whole range is written in one statement
+	timestamps := make([]int64, len(bars))
+	opens := make([]float64, len(bars))
+	highs := make([]float64, len(bars))
+	lows := make([]float64, len(bars))
+	closes := make([]float64, len(bars))
+	volumes := make([]float64, len(bars))
+	vwaps := make([]float64, len(bars))
+	trades := make([]int32, len(bars))
+	for i, bar := range bars {
+		timestamps[i] = int64(bar.Timestamp)
+		opens[i], highs[i], lows[i], closes[i] = bar.Open, bar.High, bar.Low, bar.Close
+		volumes[i], vwaps[i], trades[i] = bar.Volume, bar.VWAP, int32(bar.NumTrades)
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if len(bars) > 0 {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO price_bars (ticker, timespan, multiplier, timestamp, open, high, low, close, volume, vwap, trades)
+			SELECT $1, $2, $3, bar.*
+			FROM unnest($4::bigint[], $5::float8[], $6::float8[], $7::float8[], $8::float8[], $9::float8[], $10::float8[], $11::int[])
+				AS bar(timestamp, open, high, low, close, volume, vwap, trades)
+			ON CONFLICT (ticker, timespan, multiplier, timestamp) DO UPDATE SET
+				open = EXCLUDED.open,
+				high = EXCLUDED.high,
+				low = EXCLUDED.low,
+				close = EXCLUDED.close,
+				volume = EXCLUDED.volume,
+				vwap = EXCLUDED.vwap,
+				trades = EXCLUDED.trades
+		`, series.Ticker, series.Timespan, series.Multiplier, timestamps, opens, highs, lows, closes, volumes, vwaps, trades)
+		if err != nil {
+			return fmt.Errorf("failed to save price bars: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO price_bar_ranges (ticker, timespan, multiplier, from_date, to_date, verified_at)
+		VALUES ($1, $2, $3, $4::date, $5::date, CURRENT_TIMESTAMP)
+		ON CONFLICT (ticker, timespan, multiplier, from_date) DO UPDATE SET
+			to_date = GREATEST(price_bar_ranges.to_date, EXCLUDED.to_date)
+	`, series.Ticker, series.Timespan, series.Multiplier, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to save price range: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit price history: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) Verify(ctx context.Context, ticker string, at time.Time) error {
+	if _, err := s.db.Exec(ctx, `UPDATE price_bar_ranges SET verified_at = $2 WHERE ticker = $1`, ticker, at); err != nil {
+		return fmt.Errorf("failed to verify price history: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) Forget(ctx context.Context, ticker string) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM price_bar_ranges WHERE ticker = $1`, ticker); err != nil {
+		return fmt.Errorf("failed to delete price ranges: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM price_bars WHERE ticker = $1`, ticker); err != nil {
+		return fmt.Errorf("failed to delete price bars: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to forget price history: %w", err)
+	}
+	return nil
+}
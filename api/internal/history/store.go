@@ -0,0 +1,39 @@
+// Package history stores closed price bars so each one is downloaded from the
+// market data provider only once.
+package history
+
+import (
+	"context"
+	"time"
+
+	"github.com/cole-zoom/dUW-app/api/internal/models"
+)
+
+// Series identifies one bar size of one ticker's history
+type Series struct {
+	Ticker     string // Upper case
+	Timespan   string
+	Multiplier int
+}
+
+// Range is a span of dates (YYYY-MM-DD, inclusive, market time) whose bars have
+// all been stored. A date in a range with no bars had no trading.
+type Range struct {
+	From       string
+	To         string
+	VerifiedAt time.Time // When the bars were last known to reflect every split
+}
+
+// Store is a price history backend
+type Store interface {
+	// Ranges returns the stored date ranges of a series, oldest first.
+	Ranges(ctx context.Context, series Series) ([]Range, error)
+	// Bars returns a series' stored bars starting in [start, end), oldest first.
+	Bars(ctx context.Context, series Series, start, end time.Time) ([]models.AggregateBar, error)
+	// Save stores bars and records the dates from..to as fully stored.
+	Save(ctx context.Context, series Series, from, to string, bars []models.AggregateBar) error
+	// Verify marks all of a ticker's history as reflecting splits up to at.
+	Verify(ctx context.Context, ticker string, at time.Time) error
+	// Forget deletes all of a ticker's history, e.g. after a split changes its adjusted prices.
+	Forget(ctx context.Context, ticker string) error
+}
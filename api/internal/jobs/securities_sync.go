@@ -0,0 +1,231 @@
+// Package jobs contains long-running maintenance work that can be run once from
+// the command line or on a schedule inside the server.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/cole-zoom/dUW-app/api/internal/clients"
+	"github.com/cole-zoom/dUW-app/api/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// securitiesBatchSize is how many upserts are sent to Postgres per round trip
+const securitiesBatchSize = 500
+
+// ErrSyncRunning is returned when another process is already running the sync
+var ErrSyncRunning = errors.New("securities sync is already running")
+
+// SecuritiesSync populates the securities table from Polygon's ticker listing
+type SecuritiesSync struct {
+	db      *pgxpool.Pool
+	client  clients.APIClient
+	markets []string
+}
+
+// NewSecuritiesSync creates a sync over the given Polygon markets (e.g. "stocks", "otc")
+func NewSecuritiesSync(db *pgxpool.Pool, client clients.APIClient, markets []string) *SecuritiesSync {
+	return &SecuritiesSync{
+		db:      db,
+		client:  client,
+		markets: markets,
+	}
+}
+
+// Run walks every page of every market, upserts each ticker, then marks
+// securities in those markets that weren't seen as inactive. The run and its
+// counts are recorded in securities_sync_runs whether it succeeds or fails.
+// Only one run happens at a time across processes (e.g. the CLI and the server's
+// schedule); others return ErrSyncRunning.
+func (s *SecuritiesSync) Run(ctx context.Context) (*models.SecuritiesSyncRun, error) {
+	// Advisory locks belong to a session, so hold one connection for the whole run
+	conn, err := s.db.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	var locked bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock(hashtext('securities_sync'))").Scan(&locked); err != nil {
+		return nil, fmt.Errorf("failed to take sync lock: %w", err)
+	}
+	if !locked {
+		return nil, ErrSyncRunning
+	}
+	defer func() {
+		unlockCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer cancel()
+		if _, err := conn.Exec(unlockCtx, "SELECT pg_advisory_unlock(hashtext('securities_sync'))"); err != nil {
+			// Closing the connection releases the lock with the session
+			log.Printf("SecuritiesSync - Failed to release sync lock: %v", err)
+			conn.Conn().Close(unlockCtx)
+		}
+	}()
+
+	run := &models.SecuritiesSyncRun{Markets: s.markets, Status: "running"}
+	err = s.db.QueryRow(ctx, `
+		INSERT INTO securities_sync_runs (markets, status)
+		VALUES ($1, 'running')
+		RETURNING id, started_at
+	`, s.markets).Scan(&run.ID, &run.StartedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record sync run: %w", err)
+	}
+
+	log.Printf("SecuritiesSync - Starting run %s for markets %v", run.ID, s.markets)
+
+	runErr := s.sync(ctx, run)
+	if runErr != nil {
+		run.Status = "failed"
+		message := runErr.Error()
+		run.Error = &message
+	} else {
+		run.Status = "succeeded"
+	}
+
+	// Record the outcome even if the run's context was cancelled
+	finishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	_, err = s.db.Exec(finishCtx, `
+		UPDATE securities_sync_runs SET
+			status = $1, fetched = $2, inserted = $3, updated = $4, deactivated = $5,
+			error = $6, finished_at = $7
+		WHERE id = $8
+	`, run.Status, run.Fetched, run.Inserted, run.Updated, run.Deactivated, run.Error, finishedAt, run.ID)
+	if err != nil {
+		log.Printf("SecuritiesSync - Failed to record outcome of run %s: %v", run.ID, err)
+	}
+
+	log.Printf("SecuritiesSync - Run %s %s: fetched=%d inserted=%d updated=%d deactivated=%d",
+		run.ID, run.Status, run.Fetched, run.Inserted, run.Updated, run.Deactivated)
+
+	if runErr != nil {
+		return run, runErr
+	}
+	return run, nil
+}
+
+// sync does the work of a run, updating its counts as it goes
+func (s *SecuritiesSync) sync(ctx context.Context, run *models.SecuritiesSyncRun) error {
+	// Listing pages queue behind anything a user is waiting on. They all come from the
+	// primary provider: another lists different tickers under different types and
+	// exchanges, and anything it leaves out would be deactivated below.
+	ctx = clients.WithoutFailover(clients.WithPriority(ctx, clients.PriorityBackground))
+
+	for _, market := range s.markets {
+		batch := make([]models.PolygonTickerResponse, 0, securitiesBatchSize)
+
+		for ticker, err := range clients.ListTickers(ctx, s.client, models.TickerListParams{Market: market}, 0) {
+			if err != nil {
+				return fmt.Errorf("failed to list %s tickers: %w", market, err)
+			}
+
+			batch = append(batch, ticker)
+			if len(batch) == securitiesBatchSize {
+				if err := s.upsert(ctx, run, batch); err != nil {
+					return err
+				}
+				batch = batch[:0]
+			}
+		}
+
+		if err := s.upsert(ctx, run, batch); err != nil {
+			return err
+		}
+	}
+
+	// Anything in a synced market we didn't touch during this run has disappeared upstream
+	result, err := s.db.Exec(ctx, `
+		UPDATE securities SET active = false
+		WHERE active = true
+		  AND market = ANY($1)
+		  AND (last_synced_at IS NULL OR last_synced_at < $2)
+	`, s.markets, run.StartedAt)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate missing securities: %w", err)
+	}
+	run.Deactivated = int(result.RowsAffected())
+	return nil
+}
+
+// upsert writes one batch of tickers and counts inserts versus updates
+func (s *SecuritiesSync) upsert(ctx context.Context, run *models.SecuritiesSyncRun, tickers []models.PolygonTickerResponse) error {
+	if len(tickers) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, t := range tickers {
+		// xmax is 0 only for freshly inserted rows, which distinguishes inserts from updates
+		batch.Queue(`
+			INSERT INTO securities (
+				ticker, name, market, locale, primary_exchange, type, active,
+				currency_name, cik, composite_figi, share_class_figi, last_updated_utc, last_synced_at
+			)
+			VALUES (
+				$1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''), true,
+				NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''), NULLIF($10, ''),
+				NULLIF($11, '')::timestamptz, CURRENT_TIMESTAMP
+			)
+			ON CONFLICT (ticker) DO UPDATE SET
+				name = EXCLUDED.name,
+				market = EXCLUDED.market,
+				locale = EXCLUDED.locale,
+				primary_exchange = EXCLUDED.primary_exchange,
+				type = EXCLUDED.type,
+				active = true,
+				currency_name = EXCLUDED.currency_name,
+				cik = EXCLUDED.cik,
+				composite_figi = EXCLUDED.composite_figi,
+				share_class_figi = EXCLUDED.share_class_figi,
+				last_updated_utc = EXCLUDED.last_updated_utc,
+				last_synced_at = EXCLUDED.last_synced_at
+			RETURNING (xmax = 0) AS inserted
+		`, t.Ticker, t.Name, t.Market, t.Locale, t.PrimaryExchange, t.Type,
+			t.CurrencyName, t.Cik, t.CompositeFigi, t.ShareClassFigi, t.LastUpdatedUtc)
+	}
+
+	results := s.db.SendBatch(ctx, batch)
+	defer results.Close()
+
+	for _, t := range tickers {
+		var inserted bool
+		if err := results.QueryRow().Scan(&inserted); err != nil {
+			return fmt.Errorf("failed to upsert security %s: %w", t.Ticker, err)
+		}
+		run.Fetched++
+		if inserted {
+			run.Inserted++
+		} else {
+			run.Updated++
+		}
+	}
+	return nil
+}
+
+// Schedule runs the sync every interval until ctx is cancelled. A failed run is
+// logged and retried at the next interval.
+func (s *SecuritiesSync) Schedule(ctx context.Context, interval time.Duration) {
+	log.Printf("SecuritiesSync - Scheduled every %v", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Run(ctx); err != nil {
+				log.Printf("SecuritiesSync - Scheduled run failed: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,409 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/cole-zoom/dUW-app/api/internal/clients"
+	"github.com/cole-zoom/dUW-app/api/internal/market"
+	"github.com/cole-zoom/dUW-app/api/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	// ErrAdjustmentNotFound is returned when an adjustment doesn't exist or belongs to another user
+	ErrAdjustmentNotFound = errors.New("split adjustment not found")
+	// ErrAdjustmentResolved is returned when applying or dismissing an adjustment that isn't pending
+	ErrAdjustmentResolved = errors.New("split adjustment is not pending")
+)
+
+// splitAdjustmentColumns is the select list for split_adjustments aliased as a, with stocks as s
+const splitAdjustmentColumns = `
+	a.id, a.stock_id, s.ticker, a.execution_date, a.split_from, a.split_to, a.status,
+	a.reason, a.transaction_id, a.detected_at, a.resolved_at
+`
+
+// SplitAdjustments keeps held share counts in line with stock splits. For every
+// position held when a split executed it either records a split transaction, or,
+// when the ledger has activity on or after the split, or earlier trades that were
+// only entered after it (such as migrated opening balances), that may already
+// account for it, flags the split for the user to confirm. Each split is handled
+// once per position, so re-running never applies a split twice.
+type SplitAdjustments struct {
+	db     *pgxpool.Pool
+	client clients.APIClient
+}
+
+func NewSplitAdjustments(db *pgxpool.Pool, client clients.APIClient) *SplitAdjustments {
+	return &SplitAdjustments{
+		db:     db,
+		client: client,
+	}
+}
+
+// Run checks the split history of every held ticker
+func (j *SplitAdjustments) Run(ctx context.Context) (*models.SplitAdjustmentRun, error) {
+	// One request per held ticker; let anything a user is waiting on go first
+	ctx = clients.WithPriority(ctx, clients.PriorityBackground)
+
+	rows, err := j.db.Query(ctx, `
+		SELECT id, portfolio_id, ticker, shares, created_at, updated_at
+		FROM stocks
+		WHERE shares > 0
+		ORDER BY UPPER(ticker), created_at
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query held stocks: %w", err)
+	}
+	stocks, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.Stock])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan held stocks: %w", err)
+	}
+
+	byTicker := make(map[string][]models.Stock)
+	var tickers []string
+	for _, stock := range stocks {
+		ticker := strings.ToUpper(stock.Ticker)
+		if _, seen := byTicker[ticker]; !seen {
+			tickers = append(tickers, ticker)
+		}
+		byTicker[ticker] = append(byTicker[ticker], stock)
+	}
+
+	log.Printf("SplitAdjustments - Checking %d held tickers", len(tickers))
+	run := &models.SplitAdjustmentRun{Tickers: len(tickers)}
+	today := market.Today()
+
+	for _, ticker := range tickers {
+		splits, err := j.client.GetSplits(ctx, ticker)
+		if err != nil {
+			if ctx.Err() != nil {
+				return run, ctx.Err()
+			}
+			log.Printf("SplitAdjustments - Failed to get splits for %s: %v", ticker, err)
+			run.Failed++
+			continue
+		}
+
+		for _, split := range splits.Results {
+			if split.ExecutionDate == "" || split.ExecutionDate > today || split.SplitFrom <= 0 || split.SplitTo <= 0 {
+				continue
+			}
+			executionDate, err := time.Parse("2006-01-02", split.ExecutionDate)
+			if err != nil {
+				continue
+			}
+
+			for _, stock := range byTicker[ticker] {
+				status, err := j.adjust(ctx, stock.ID, split, executionDate)
+				if err != nil {
+					return run, fmt.Errorf("failed to adjust %s for its %s split: %w", ticker, split.ExecutionDate, err)
+				}
+				switch status {
+				case models.SplitAdjustmentApplied:
+					log.Printf("SplitAdjustments - Applied %s %v-for-%v split of %s to stock %s", split.ExecutionDate, split.SplitTo, split.SplitFrom, ticker, stock.ID)
+					run.Applied++
+				case models.SplitAdjustmentPending:
+					log.Printf("SplitAdjustments - Flagged %s %v-for-%v split of %s on stock %s for confirmation", split.ExecutionDate, split.SplitTo, split.SplitFrom, ticker, stock.ID)
+					run.Pending++
+				}
+			}
+		}
+	}
+
+	log.Printf("SplitAdjustments - Done: tickers=%d failed=%d applied=%d pending=%d", run.Tickers, run.Failed, run.Applied, run.Pending)
+	return run, nil
+}
+
+// adjust handles one split for one position and returns the status it recorded,
+// or "" when there was nothing to do (already handled, or not held at the time)
+func (j *SplitAdjustments) adjust(ctx context.Context, stockID string, split models.Split, executionDate time.Time) (string, error) {
+	tx, err := j.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the position so a concurrent run or trade sees the ledger after this one
+	if _, err := tx.Exec(ctx, "SELECT 1 FROM stocks WHERE id = $1 FOR UPDATE", stockID); err != nil {
+		return "", fmt.Errorf("failed to lock stock: %w", err)
+	}
+
+	var handled bool
+	err = tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM split_adjustments WHERE stock_id = $1 AND execution_date = $2)", stockID, executionDate).Scan(&handled)
+	if err != nil {
+		return "", fmt.Errorf("failed to check split adjustments: %w", err)
+	}
+	if handled {
+		return "", nil
+	}
+
+	ledger, err := loadLedger(ctx, tx, stockID)
+	if err != nil {
+		return "", err
+	}
+
+	var before, after []models.Transaction
+	recorded := false
+	enteredLate := 0 // Trades before the split that were entered once it had executed
+	for _, t := range ledger {
+		switch {
+		case t.TradeDate.Before(executionDate):
+			before = append(before, t)
+			if t.CreatedAt.In(market.Location()).Format("2006-01-02") >= split.ExecutionDate {
+				enteredLate++
+			}
+		case t.Type == models.TransactionSplit && t.TradeDate.Equal(executionDate):
+			recorded = true
+		default:
+			after = append(after, t)
+		}
+	}
+
+	// Positions opened after the split were bought at post-split share counts
+	if held, err := models.DeriveShares(before); err != nil || held == 0 {
+		return "", nil
+	}
+
+	var status string
+	var reason *string
+	switch {
+	case recorded:
+		status = models.SplitAdjustmentRecorded
+	case len(after) > 0:
+		// Later trades may have been entered at post-split counts, or to correct for the split by hand
+		status = models.SplitAdjustmentPending
+		message := fmt.Sprintf("The position has %d transaction(s) on or after the split; confirm they don't already account for it", len(after))
+		reason = &message
+	case enteredLate > 0:
+		// Entered with hindsight, so the share counts may already be post-split
+		status = models.SplitAdjustmentPending
+		message := fmt.Sprintf("%d transaction(s) before the split were entered after it executed; confirm their share counts don't already account for it", enteredLate)
+		reason = &message
+	default:
+		status = models.SplitAdjustmentApplied
+	}
+
+	var transactionID *string
+	if status == models.SplitAdjustmentApplied {
+		id, err := recordSplit(ctx, tx, stockID, split, executionDate)
+		if err != nil {
+			return "", err
+		}
+		transactionID = &id
+	}
+	var resolvedAt *time.Time
+	if status != models.SplitAdjustmentPending {
+		now := time.Now()
+		resolvedAt = &now
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO split_adjustments (stock_id, execution_date, split_from, split_to, status, reason, transaction_id, resolved_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, stockID, executionDate, split.SplitFrom, split.SplitTo, status, reason, transactionID, resolvedAt)
+	if err != nil {
+		return "", fmt.Errorf("failed to record split adjustment: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+	return status, nil
+}
+
+// List returns the adjustments for a portfolio owned by userID, newest split first,
+// optionally only those with the given status
+func (j *SplitAdjustments) List(ctx context.Context, userID, portfolioID, status string) ([]models.SplitAdjustment, error) {
+	rows, err := j.db.Query(ctx, `
+		SELECT `+splitAdjustmentColumns+`
+		FROM split_adjustments a
+		JOIN stocks s ON a.stock_id = s.id
+		JOIN portfolios p ON s.portfolio_id = p.id
+		WHERE s.portfolio_id::text = $1
+		  AND p.user_id = $2
+		  AND ($3 = '' OR a.status = $3)
+		ORDER BY a.execution_date DESC, s.ticker ASC
+	`, portfolioID, userID, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query split adjustments: %w", err)
+	}
+	adjustments, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.SplitAdjustment])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan split adjustments: %w", err)
+	}
+	return adjustments, nil
+}
+
+// Apply records a pending adjustment's split transaction on behalf of the user who owns it.
+// Returns models.ErrOversold if the rescaled ledger leaves a later sell without enough shares.
+func (j *SplitAdjustments) Apply(ctx context.Context, userID, portfolioID, adjustmentID string) (*models.SplitAdjustment, error) {
+	tx, err := j.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	adjustment, err := lockAdjustment(ctx, tx, userID, portfolioID, adjustmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	split := models.Split{
+		Ticker:        adjustment.Ticker,
+		ExecutionDate: adjustment.ExecutionDate.Format("2006-01-02"),
+		SplitFrom:     adjustment.SplitFrom,
+		SplitTo:       adjustment.SplitTo,
+	}
+	transactionID, err := recordSplit(ctx, tx, adjustment.StockID, split, adjustment.ExecutionDate)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(ctx, `
+		UPDATE split_adjustments SET status = 'applied', transaction_id = $1, resolved_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+		RETURNING transaction_id, resolved_at
+	`, transactionID, adjustment.ID).Scan(&adjustment.TransactionID, &adjustment.ResolvedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update split adjustment: %w", err)
+	}
+	adjustment.Status = models.SplitAdjustmentApplied
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return adjustment, nil
+}
+
+// Dismiss marks a pending adjustment as not needed, leaving the ledger untouched
+func (j *SplitAdjustments) Dismiss(ctx context.Context, userID, portfolioID, adjustmentID string) (*models.SplitAdjustment, error) {
+	tx, err := j.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	adjustment, err := lockAdjustment(ctx, tx, userID, portfolioID, adjustmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(ctx, `
+		UPDATE split_adjustments SET status = 'dismissed', resolved_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING resolved_at
+	`, adjustment.ID).Scan(&adjustment.ResolvedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update split adjustment: %w", err)
+	}
+	adjustment.Status = models.SplitAdjustmentDismissed
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return adjustment, nil
+}
+
+// Schedule runs the job every interval until ctx is cancelled. A failed run is
+// logged and retried at the next interval.
+func (j *SplitAdjustments) Schedule(ctx context.Context, interval time.Duration) {
+	log.Printf("SplitAdjustments - Scheduled every %v", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := j.Run(ctx); err != nil {
+				log.Printf("SplitAdjustments - Scheduled run failed: %v", err)
+			}
+		}
+	}
+}
+
+// lockAdjustment loads a pending adjustment in a portfolio owned by userID, locking
+// it and its position for the rest of the transaction
+func lockAdjustment(ctx context.Context, tx pgx.Tx, userID, portfolioID, adjustmentID string) (*models.SplitAdjustment, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT `+splitAdjustmentColumns+`
+		FROM split_adjustments a
+		JOIN stocks s ON a.stock_id = s.id
+		JOIN portfolios p ON s.portfolio_id = p.id
+		WHERE a.id::text = $1 AND s.portfolio_id::text = $2 AND p.user_id = $3
+		FOR UPDATE OF a, s
+	`, adjustmentID, portfolioID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load split adjustment: %w", err)
+	}
+	adjustment, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[models.SplitAdjustment])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrAdjustmentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load split adjustment: %w", err)
+	}
+	if adjustment.Status != models.SplitAdjustmentPending {
+		return nil, ErrAdjustmentResolved
+	}
+	return adjustment, nil
+}
+
+// recordSplit adds a split transaction to a position's ledger and re-derives its share count
+func recordSplit(ctx context.Context, tx pgx.Tx, stockID string, split models.Split, executionDate time.Time) (string, error) {
+	ratio := split.Ratio()
+	notes := fmt.Sprintf("%v-for-%v split", split.SplitTo, split.SplitFrom)
+
+	var transactionID string
+	err := tx.QueryRow(ctx, `
+		INSERT INTO transactions (stock_id, type, shares, price, fees, split_ratio, trade_date, notes)
+		VALUES ($1, 'split', 0, 0, 0, $2, $3, $4)
+		RETURNING id
+	`, stockID, ratio, executionDate, notes).Scan(&transactionID)
+	if err != nil {
+		return "", fmt.Errorf("failed to insert split transaction: %w", err)
+	}
+
+	ledger, err := loadLedger(ctx, tx, stockID)
+	if err != nil {
+		return "", err
+	}
+	shares, err := models.DeriveShares(ledger)
+	if err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(ctx, "UPDATE stocks SET shares = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2", shares, stockID); err != nil {
+		return "", fmt.Errorf("failed to update derived shares: %w", err)
+	}
+	return transactionID, nil
+}
+
+// loadLedger returns the fields of a position's transactions needed to replay its
+// share count, and when each was entered, oldest first
+func loadLedger(ctx context.Context, tx pgx.Tx, stockID string) ([]models.Transaction, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT type, shares, split_ratio, trade_date, created_at
+		FROM transactions
+		WHERE stock_id = $1
+		ORDER BY trade_date ASC, created_at ASC
+	`, stockID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transactions: %w", err)
+	}
+	ledger, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Transaction, error) {
+		var t models.Transaction
+		err := row.Scan(&t.Type, &t.Shares, &t.SplitRatio, &t.TradeDate, &t.CreatedAt)
+		return t, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan transactions: %w", err)
+	}
+	return ledger, nil
+}
@@ -0,0 +1,267 @@
+package market
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Extended hours around the regular session, in exchange local time
+const (
+	preMarketHour   = 4
+	afterHoursHour  = 20
+	earlyCloseHour  = 13
+	earlyAfterHours = 17 // After-hours trading also ends early on early close days
+)
+
+// Phase is where the market is in its trading day
+type Phase string
+
+const (
+	PhaseOpen       Phase = "open"
+	PhasePreMarket  Phase = "pre-market"
+	PhaseAfterHours Phase = "after-hours"
+	PhaseClosed     Phase = "closed"
+)
+
+// Holiday is a date the exchange is closed or closes early
+type Holiday struct {
+	Date  string // YYYY-MM-DD
+	Name  string
+	Close time.Time // Early close; zero when the exchange is closed all day
+}
+
+// Closed reports whether the exchange doesn't open at all on the holiday
+func (h Holiday) Closed() bool {
+	return h.Close.IsZero()
+}
+
+// Session is one regular trading session
+type Session struct {
+	Open       time.Time
+	Close      time.Time
+	EarlyClose bool
+}
+
+// Date returns the session's date formatted as YYYY-MM-DD
+func (s Session) Date() string {
+	return s.Open.Format("2006-01-02")
+}
+
+// The calendar follows NYSE holiday rules, overridden by holidays announced by the
+// market data provider, which also cover unscheduled closures
+var calendar = struct {
+	sync.RWMutex
+	announced map[string]Holiday
+	rules     map[int]map[string]Holiday // By year
+}{
+	announced: map[string]Holiday{},
+	rules:     map[int]map[string]Holiday{},
+}
+
+// SetHolidays records holidays announced by the market data provider; they take
+// precedence over the built-in rules for their dates
+func SetHolidays(holidays []Holiday) {
+	calendar.Lock()
+	defer calendar.Unlock()
+	for _, h := range holidays {
+		calendar.announced[h.Date] = h
+	}
+}
+
+// HolidayOn returns the holiday falling on t's date in market time, if any
+func HolidayOn(t time.Time) (Holiday, bool) {
+	local := t.In(Location())
+	date := local.Format("2006-01-02")
+
+	calendar.RLock()
+	h, ok := calendar.announced[date]
+	rules, cached := calendar.rules[local.Year()]
+	calendar.RUnlock()
+	if ok {
+		return h, true
+	}
+
+	if !cached {
+		rules = holidayRules(local.Year())
+		calendar.Lock()
+		calendar.rules[local.Year()] = rules
+		calendar.Unlock()
+	}
+	h, ok = rules[date]
+	return h, ok
+}
+
+// Holidays lists the holidays on dates in [from, to], oldest first
+func Holidays(from, to time.Time) []Holiday {
+	var holidays []Holiday
+	for day := startOfDay(from); !day.After(to); day = day.AddDate(0, 0, 1) {
+		if h, ok := HolidayOn(day); ok && !IsWeekend(day) {
+			holidays = append(holidays, h)
+		}
+	}
+	return holidays
+}
+
+// IsTradingDay reports whether the exchange has a regular session on t's date
+func IsTradingDay(t time.Time) bool {
+	_, ok := SessionOn(t)
+	return ok
+}
+
+// SessionOn returns the regular session on t's date in market time
+func SessionOn(t time.Time) (Session, bool) {
+	local := t.In(Location())
+	if IsWeekend(local) {
+		return Session{}, false
+	}
+	session := Session{
+		Open:  time.Date(local.Year(), local.Month(), local.Day(), openHour, openMinute, 0, 0, local.Location()),
+		Close: time.Date(local.Year(), local.Month(), local.Day(), closeHour, closeMinute, 0, 0, local.Location()),
+	}
+	if h, ok := HolidayOn(local); ok {
+		if h.Closed() {
+			return Session{}, false
+		}
+		session.Close = h.Close.In(Location())
+		session.EarlyClose = true
+	}
+	return session, true
+}
+
+// NextClose returns the next regular session close strictly after t
+func NextClose(t time.Time) time.Time {
+	for day := startOfDay(t); ; day = day.AddDate(0, 0, 1) {
+		if session, ok := SessionOn(day); ok && session.Close.After(t) {
+			return session.Close
+		}
+	}
+}
+
+// LastSessions returns the n most recent sessions that had opened by t, oldest
+// first; the latest may still be in progress
+func LastSessions(t time.Time, n int) []Session {
+	sessions := make([]Session, 0, n)
+	for day := startOfDay(t); len(sessions) < n; day = day.AddDate(0, 0, -1) {
+		if session, ok := SessionOn(day); ok && !session.Open.After(t) {
+			sessions = append(sessions, session)
+		}
+	}
+	slices.Reverse(sessions)
+	return sessions
+}
+
+// PhaseAt returns where the market is in its trading day at t
+func PhaseAt(t time.Time) Phase {
+	session, ok := SessionOn(t)
+	if !ok {
+		return PhaseClosed
+	}
+	local := t.In(Location())
+	preMarket := time.Date(local.Year(), local.Month(), local.Day(), preMarketHour, 0, 0, 0, local.Location())
+	afterHours := time.Date(local.Year(), local.Month(), local.Day(), afterHoursHour, 0, 0, 0, local.Location())
+	if session.EarlyClose {
+		afterHours = time.Date(local.Year(), local.Month(), local.Day(), earlyAfterHours, 0, 0, 0, local.Location())
+	}
+
+	switch {
+	case local.Before(preMarket):
+		return PhaseClosed
+	case local.Before(session.Open):
+		return PhasePreMarket
+	case local.Before(session.Close):
+		return PhaseOpen
+	case local.Before(afterHours):
+		return PhaseAfterHours
+	default:
+		return PhaseClosed
+	}
+}
+
+// startOfDay returns midnight market time on t's date
+func startOfDay(t time.Time) time.Time {
+	local := t.In(Location())
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+}
+
+// holidayRules computes a year's NYSE holidays and early closes
+func holidayRules(year int) map[string]Holiday {
+	loc := Location()
+	date := func(month time.Month, day int) time.Time { return time.Date(year, month, day, 0, 0, 0, 0, loc) }
+
+	holidays := map[string]Holiday{}
+	closed := func(name string, day time.Time) {
+		holidays[day.Format("2006-01-02")] = Holiday{Date: day.Format("2006-01-02"), Name: name}
+	}
+	earlyClose := func(name string, day time.Time) {
+		key := day.Format("2006-01-02")
+		if _, isHoliday := holidays[key]; isHoliday || IsWeekend(day) {
+			return
+		}
+		holidays[key] = Holiday{Date: key, Name: name, Close: time.Date(year, day.Month(), day.Day(), earlyCloseHour, 0, 0, 0, loc)}
+	}
+
+	// New Year's Day moves to Monday from a Sunday but isn't made up from a Saturday
+	if newYear := date(time.January, 1); newYear.Weekday() != time.Saturday {
+		closed("New Year's Day", observed(newYear))
+	}
+	closed("Martin Luther King Jr. Day", nthWeekday(year, time.January, time.Monday, 3))
+	closed("Washington's Birthday", nthWeekday(year, time.February, time.Monday, 3))
+	closed("Good Friday", easter(year).AddDate(0, 0, -2))
+	closed("Memorial Day", lastWeekday(year, time.May, time.Monday))
+	if year >= 2022 {
+		closed("Juneteenth National Independence Day", observed(date(time.June, 19)))
+	}
+	closed("Independence Day", observed(date(time.July, 4)))
+	closed("Labor Day", nthWeekday(year, time.September, time.Monday, 1))
+	thanksgiving := nthWeekday(year, time.November, time.Thursday, 4)
+	closed("Thanksgiving Day", thanksgiving)
+	closed("Christmas Day", observed(date(time.December, 25)))
+
+	earlyClose("Independence Day", date(time.July, 3))
+	earlyClose("Thanksgiving Day", thanksgiving.AddDate(0, 0, 1))
+	earlyClose("Christmas Day", date(time.December, 24))
+	return holidays
+}
+
+// observed moves a holiday on a Saturday to Friday and one on a Sunday to Monday
+func observed(day time.Time) time.Time {
+	switch day.Weekday() {
+	case time.Saturday:
+		return day.AddDate(0, 0, -1)
+	case time.Sunday:
+		return day.AddDate(0, 0, 1)
+	default:
+		return day
+	}
+}
+
+// nthWeekday returns the nth given weekday of a month, e.g. the third Monday
+func nthWeekday(year int, month time.Month, weekday time.Weekday, n int) time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, Location())
+	offset := (int(weekday) - int(first.Weekday()) + 7) % 7
+	return first.AddDate(0, 0, offset+7*(n-1))
+}
+
+// lastWeekday returns the last given weekday of a month
+func lastWeekday(year int, month time.Month, weekday time.Weekday) time.Time {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, Location())
+	offset := (int(last.Weekday()) - int(weekday) + 7) % 7
+	return last.AddDate(0, 0, -offset)
+}
+
+// easter returns Western Easter Sunday (anonymous Gregorian algorithm)
+func easter(year int) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, Location())
+}
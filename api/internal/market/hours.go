@@ -0,0 +1,42 @@
+// Package market holds US equity market time helpers shared by the clients and services.
+package market
+
+import "time"
+
+// Regular session hours in exchange local time
+const (
+	openHour    = 9
+	openMinute  = 30
+	closeHour   = 16
+	closeMinute = 0
+)
+
+// Location returns US market time, falling back to a fixed EST offset
+// when the host has no timezone database.
+func Location() *time.Location {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		return time.FixedZone("EST", -5*60*60)
+	}
+	return loc
+}
+
+// Today returns the current date in market time formatted as YYYY-MM-DD
+func Today() string {
+	return time.Now().In(Location()).Format("2006-01-02")
+}
+
+// IsWeekend reports whether t falls on a Saturday or Sunday
+func IsWeekend(t time.Time) bool {
+	return t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+}
+
+// NextOpen returns the next regular session open strictly after t, skipping
+// weekends and exchange holidays
+func NextOpen(t time.Time) time.Time {
+	for day := startOfDay(t); ; day = day.AddDate(0, 0, 1) {
+		if session, ok := SessionOn(day); ok && session.Open.After(t) {
+			return session.Open
+		}
+	}
+}
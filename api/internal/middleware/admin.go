@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// RequireAdmin only lets through users listed in ADMIN_USER_IDS (comma separated).
+// It must run after JWTAuth so the userID is in the request context.
+func RequireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	admins := make(map[string]bool)
+	for _, id := range strings.Split(os.Getenv("ADMIN_USER_IDS"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			admins[id] = true
+		}
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, _ := r.Context().Value("userID").(string)
+		if !admins[userID] {
+			log.Printf("[Admin] Denied %s %s for userID %q", r.Method, r.URL.Path, userID)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Admin access required",
+			})
+			return
+		}
+		next(w, r)
+	}
+}
@@ -68,6 +68,7 @@ func CORS(next http.Handler) http.Handler {
 
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, userID")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Next-Cursor, X-Range-From, X-Range-To, X-Range-Bar")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Deadline gives every request's context a deadline of timeout from arrival, so
+// work done for it (database queries, upstream calls, waits for a rate limit slot)
+// stops once the response could no longer be written, and calls that can't be
+// served in time are turned away up front.
+func Deadline(next http.Handler, timeout time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
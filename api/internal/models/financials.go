@@ -0,0 +1,133 @@
+package models
+
+import "encoding/json"
+
+// FinancialValue is one line item from a financial statement. Polygon reports each
+// as an object ({"value": 1.5e9, "unit": "USD", "label": "Revenues"}); we keep just
+// the value and write it back out as a plain number.
+type FinancialValue float64
+
+func (f *FinancialValue) UnmarshalJSON(data []byte) error {
+	// Plain numbers come back from our own cache and fixtures
+	var n float64
+	if err := json.Unmarshal(data, &n); err == nil {
+		*f = FinancialValue(n)
+		return nil
+	}
+
+	var item struct {
+		Value float64 `json:"value"`
+	}
+	if err := json.Unmarshal(data, &item); err != nil {
+		return err
+	}
+	*f = FinancialValue(item.Value)
+	return nil
+}
+
+// Float returns the value, or nil when the line item wasn't reported
+func (f *FinancialValue) Float() *float64 {
+	if f == nil {
+		return nil
+	}
+	v := float64(*f)
+	return &v
+}
+
+// IncomeStatement holds the income statement line items we use; nil means not reported
+type IncomeStatement struct {
+	Revenues                          *FinancialValue `json:"revenues,omitempty"`
+	CostOfRevenue                     *FinancialValue `json:"cost_of_revenue,omitempty"`
+	GrossProfit                       *FinancialValue `json:"gross_profit,omitempty"`
+	ResearchAndDevelopment            *FinancialValue `json:"research_and_development,omitempty"`
+	SellingGeneralAndAdministrative   *FinancialValue `json:"selling_general_and_administrative_expenses,omitempty"`
+	OperatingExpenses                 *FinancialValue `json:"operating_expenses,omitempty"`
+	OperatingIncomeLoss               *FinancialValue `json:"operating_income_loss,omitempty"`
+	InterestExpense                   *FinancialValue `json:"interest_expense_operating,omitempty"`
+	IncomeBeforeTax                   *FinancialValue `json:"income_loss_from_continuing_operations_before_tax,omitempty"`
+	IncomeTaxExpense                  *FinancialValue `json:"income_tax_expense_benefit,omitempty"`
+	NetIncomeLoss                     *FinancialValue `json:"net_income_loss,omitempty"`
+	NetIncomeLossAttributableToParent *FinancialValue `json:"net_income_loss_attributable_to_parent,omitempty"`
+	BasicEarningsPerShare             *FinancialValue `json:"basic_earnings_per_share,omitempty"`
+	DilutedEarningsPerShare           *FinancialValue `json:"diluted_earnings_per_share,omitempty"`
+}
+
+// BalanceSheet holds the balance sheet line items we use; nil means not reported
+type BalanceSheet struct {
+	Assets                     *FinancialValue `json:"assets,omitempty"`
+	CurrentAssets              *FinancialValue `json:"current_assets,omitempty"`
+	NoncurrentAssets           *FinancialValue `json:"noncurrent_assets,omitempty"`
+	Inventory                  *FinancialValue `json:"inventory,omitempty"`
+	Liabilities                *FinancialValue `json:"liabilities,omitempty"`
+	CurrentLiabilities         *FinancialValue `json:"current_liabilities,omitempty"`
+	NoncurrentLiabilities      *FinancialValue `json:"noncurrent_liabilities,omitempty"`
+	AccountsPayable            *FinancialValue `json:"accounts_payable,omitempty"`
+	LongTermDebt               *FinancialValue `json:"long_term_debt,omitempty"`
+	Equity                     *FinancialValue `json:"equity,omitempty"`
+	EquityAttributableToParent *FinancialValue `json:"equity_attributable_to_parent,omitempty"`
+	LiabilitiesAndEquity       *FinancialValue `json:"liabilities_and_equity,omitempty"`
+}
+
+// CashFlowStatement holds the cash flow line items we use; nil means not reported
+type CashFlowStatement struct {
+	OperatingActivities *FinancialValue `json:"net_cash_flow_from_operating_activities,omitempty"`
+	InvestingActivities *FinancialValue `json:"net_cash_flow_from_investing_activities,omitempty"`
+	FinancingActivities *FinancialValue `json:"net_cash_flow_from_financing_activities,omitempty"`
+	NetCashFlow         *FinancialValue `json:"net_cash_flow,omitempty"`
+}
+
+// FinancialStatements groups the statements from one filing
+type FinancialStatements struct {
+	IncomeStatement   IncomeStatement   `json:"income_statement"`
+	BalanceSheet      BalanceSheet      `json:"balance_sheet"`
+	CashFlowStatement CashFlowStatement `json:"cash_flow_statement"`
+}
+
+// FinancialReport is one fiscal period's statements from Polygon financials endpoint
+type FinancialReport struct {
+	StartDate    string              `json:"start_date"` // YYYY-MM-DD
+	EndDate      string              `json:"end_date"`   // YYYY-MM-DD
+	FilingDate   string              `json:"filing_date,omitempty"`
+	FiscalPeriod string              `json:"fiscal_period"` // Q1-Q4, or FY
+	FiscalYear   string              `json:"fiscal_year"`
+	Timeframe    string              `json:"timeframe"` // quarterly or annual
+	CompanyName  string              `json:"company_name"`
+	CIK          string              `json:"cik"`
+	Financials   FinancialStatements `json:"financials"`
+}
+
+// FinancialsResponse represents the response from Polygon financials endpoint, newest period first
+type FinancialsResponse struct {
+	Ticker    string            `json:"ticker"`
+	Timeframe string            `json:"timeframe"`
+	Results   []FinancialReport `json:"results"`
+	Status    string            `json:"status"`
+	RequestID string            `json:"request_id"`
+	Source    string            `json:"source,omitempty"` // Provider that served the data
+}
+
+// FinancialRatios are derived from the latest statements and the previous close.
+// Each is nil when an input is missing or the ratio isn't meaningful (e.g. P/E on a loss).
+type FinancialRatios struct {
+	PriceToEarnings     *float64 `json:"price_to_earnings"`
+	PriceToBook         *float64 `json:"price_to_book"`
+	GrossMargin         *float64 `json:"gross_margin"`     // Percent of revenue
+	OperatingMargin     *float64 `json:"operating_margin"` // Percent of revenue
+	NetMargin           *float64 `json:"net_margin"`       // Percent of revenue
+	ReturnOnEquity      *float64 `json:"return_on_equity"` // Percent
+	DebtToEquity        *float64 `json:"debt_to_equity"`   // Long-term debt over equity
+	LiabilitiesToEquity *float64 `json:"liabilities_to_equity"`
+}
+
+// Fundamentals is a ticker's recent financial statements with ratios derived from them
+type Fundamentals struct {
+	Ticker     string            `json:"ticker"`
+	Timeframe  string            `json:"timeframe"`
+	Price      *float64          `json:"price"`      // Previous close used for the ratios
+	PriceDate  string            `json:"price_date"` // YYYY-MM-DD of that close
+	PeriodEnd  string            `json:"period_end"` // End of the latest period the ratios use
+	Trailing   bool              `json:"trailing"`   // Income and margins cover the trailing four quarters
+	Ratios     FinancialRatios   `json:"ratios"`
+	Statements []FinancialReport `json:"statements"`
+	Source     string            `json:"source,omitempty"` // Provider that served the statements
+}
@@ -0,0 +1,32 @@
+package models
+
+// HoldingIncome is the dividend outlook for one ticker in a portfolio. Amounts are
+// in Currency, the currency the dividends are paid in.
+type HoldingIncome struct {
+	Ticker            string   `json:"ticker"`
+	Shares            float64  `json:"shares"`
+	Currency          string   `json:"currency"`           // Dividend currency, e.g. "USD"
+	ListingCurrency   string   `json:"listing_currency"`   // Currency the shares trade in, from ticker details
+	Frequency         int      `json:"frequency"`          // Regular payments per year; 0 when none are expected
+	TrailingDividend  float64  `json:"trailing_dividend"`  // Per share paid over the last 12 months
+	ProjectedDividend float64  `json:"projected_dividend"` // Per share expected over the next 12 months
+	ProjectedIncome   float64  `json:"projected_income"`   // ProjectedDividend for every share held
+	TrailingYield     *float64 `json:"trailing_yield"`     // Percent of the current price; nil without a price or when currencies differ
+	YieldOnCost       *float64 `json:"yield_on_cost"`      // Projected income as a percent of cost basis; nil without one
+}
+
+// MonthlyIncome is the dividend cash expected in one calendar month
+type MonthlyIncome struct {
+	Month   string             `json:"month"`   // YYYY-MM
+	Amounts map[string]float64 `json:"amounts"` // By currency; currencies are never converted
+}
+
+// PortfolioIncome projects a portfolio's dividend cash flow over the next 12 months
+type PortfolioIncome struct {
+	PortfolioID string             `json:"portfolio_id"`
+	AsOf        string             `json:"as_of"` // YYYY-MM-DD the projection starts from
+	Months      []MonthlyIncome    `json:"months"`
+	Totals      map[string]float64 `json:"totals"` // Projected 12-month income by currency
+	Holdings    []HoldingIncome    `json:"holdings"`
+	Unavailable []string           `json:"unavailable"` // Tickers whose dividend history couldn't be fetched
+}
@@ -0,0 +1,57 @@
+package models
+
+// MarketStatusResponse represents the response from Polygon market status endpoint
+type MarketStatusResponse struct {
+	Market     string            `json:"market"` // open, closed, or extended-hours
+	ServerTime string            `json:"serverTime"`
+	EarlyHours bool              `json:"earlyHours"`
+	AfterHours bool              `json:"afterHours"`
+	Exchanges  map[string]string `json:"exchanges"`        // Status by exchange, e.g. "nyse": "open"
+	Source     string            `json:"source,omitempty"` // Provider that served the data
+}
+
+// MarketHoliday is an upcoming exchange holiday from Polygon upcoming market status endpoint
+type MarketHoliday struct {
+	Exchange string `json:"exchange"`
+	Name     string `json:"name"`
+	Date     string `json:"date"`            // YYYY-MM-DD
+	Status   string `json:"status"`          // closed or early-close
+	Open     string `json:"open,omitempty"`  // RFC 3339, early closes only
+	Close    string `json:"close,omitempty"` // RFC 3339, early closes only
+}
+
+// MarketHolidaysResponse lists upcoming holidays across exchanges, soonest first
+type MarketHolidaysResponse struct {
+	Results []MarketHoliday `json:"results"`
+	Source  string          `json:"source,omitempty"` // Provider that served the data
+}
+
+// MarketSession is one regular trading session
+type MarketSession struct {
+	Date       string `json:"date"`  // YYYY-MM-DD
+	Open       string `json:"open"`  // RFC 3339
+	Close      string `json:"close"` // RFC 3339
+	EarlyClose bool   `json:"early_close"`
+}
+
+// MarketCalendarDay is a date the exchange is closed or closes early
+type MarketCalendarDay struct {
+	Date   string `json:"date"` // YYYY-MM-DD
+	Name   string `json:"name"`
+	Status string `json:"status"`          // closed or early-close
+	Close  string `json:"close,omitempty"` // RFC 3339, early closes only
+}
+
+// MarketStatus is whether US equities are trading now, with the surrounding calendar
+type MarketStatus struct {
+	Status    string              `json:"status"`  // open, pre-market, after-hours, or closed
+	IsOpen    bool                `json:"is_open"` // In the regular session
+	AsOf      string              `json:"as_of"`   // RFC 3339
+	Session   *MarketSession      `json:"session"` // Today's regular session; nil when the market doesn't open today
+	Holiday   *MarketCalendarDay  `json:"holiday"` // Today's holiday or early close, if any
+	NextOpen  string              `json:"next_open"`
+	NextClose string              `json:"next_close"`
+	Upcoming  []MarketCalendarDay `json:"upcoming"`            // Holidays and early closes in the next 90 days
+	Exchanges map[string]string   `json:"exchanges,omitempty"` // Status by exchange, when the provider reports it
+	Source    string              `json:"source"`              // Provider that reported the status, or "calendar"
+}
@@ -0,0 +1,9 @@
+package models
+
+// PortfolioNews is one page of the merged news feed for a portfolio's holdings
+type PortfolioNews struct {
+	PortfolioID string        `json:"portfolio_id"`
+	Results     []NewsArticle `json:"results"`               // Newest first, each article once even when it names several holdings
+	NextCursor  string        `json:"next_cursor,omitempty"` // Empty on the last page
+	Unavailable []string      `json:"unavailable"`           // Tickers whose news couldn't be fetched
+}
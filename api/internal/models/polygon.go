@@ -55,6 +55,37 @@ type PolygonAPIResponse struct {
 	NextURL   string                  `json:"next_url"`
 }
 
+// TickerListParams filters a paginated ticker listing
+type TickerListParams struct {
+	Search string // Matches ticker or company name
+	Market string // e.g. "stocks", "otc"; empty for all markets
+	Type   string // e.g. "CS", "ETF"; empty for all types
+	Limit  int    // Page size, at most 1000 (the default)
+	Cursor string // Continuation cursor from a previous page
+}
+
+// TickerPage is one page of a ticker listing
+type TickerPage struct {
+	Results    []PolygonTickerResponse `json:"results"`
+	NextCursor string                  `json:"next_cursor,omitempty"` // Empty on the last page
+	Source     string                  `json:"source,omitempty"`      // Provider that served the page
+}
+
+// ToDisplayStocks converts Polygon tickers into suggestion rows
+func ToDisplayStocks(tickers []PolygonTickerResponse) []DisplayStock {
+	stocks := make([]DisplayStock, 0, len(tickers))
+	for _, polygonTicker := range tickers {
+		stocks = append(stocks, DisplayStock{
+			Ticker:          polygonTicker.Ticker,
+			Name:            polygonTicker.Name,
+			Shares:          0, // Default to 0 shares since this is for suggestions
+			PrimaryExchange: polygonTicker.PrimaryExchange,
+			CurrencyName:    polygonTicker.CurrencyName,
+		})
+	}
+	return stocks
+}
+
 // AggregateBar represents a single OHLC bar from Polygon aggregates endpoint
 type AggregateBar struct {
 	Ticker    string        `json:"T,omitempty"` // Ticker symbol (uppercase T) - included in some endpoints
@@ -77,6 +108,7 @@ type AggregatesResponse struct {
 	Results      []AggregateBar `json:"results"`
 	Status       string         `json:"status"`
 	RequestID    string         `json:"request_id"`
+	Source       string         `json:"source,omitempty"` // Provider that served the data
 }
 
 // TickerDetails represents detailed information about a ticker
@@ -96,6 +128,7 @@ type TickerDetails struct {
 	HomepageURL                 string  `json:"homepage_url"`
 	TotalEmployees              int     `json:"total_employees"`
 	ListDate                    string  `json:"list_date"`
+	Source                      string  `json:"source,omitempty"` // Provider that served the data
 }
 
 // TickerDetailsResponse represents the response from Polygon ticker details endpoint
@@ -114,4 +147,109 @@ type PreviousCloseResponse struct {
 	Results      []AggregateBar `json:"results"`
 	Status       string         `json:"status"`
 	RequestID    string         `json:"request_id"`
+	Source       string         `json:"source,omitempty"` // Provider that served the data
+}
+
+// GroupedDailyResponse represents the response from Polygon grouped daily endpoint.
+// Each bar carries its ticker in the T field.
+type GroupedDailyResponse struct {
+	QueryCount   int            `json:"queryCount"`
+	ResultsCount int            `json:"resultsCount"`
+	Adjusted     bool           `json:"adjusted"`
+	Results      []AggregateBar `json:"results"`
+	Status       string         `json:"status"`
+	RequestID    string         `json:"request_id"`
+	Source       string         `json:"source,omitempty"` // Provider that served the data
+}
+
+// Dividend represents a single cash dividend from Polygon reference data
+type Dividend struct {
+	Ticker          string  `json:"ticker"`
+	CashAmount      float64 `json:"cash_amount"`      // Per share, in Currency
+	Currency        string  `json:"currency"`         // e.g. "USD"
+	DividendType    string  `json:"dividend_type"`    // CD regular, SC special, LT/ST capital gains
+	Frequency       int     `json:"frequency"`        // Payments per year: 0 one-time, 1, 2, 4, 12
+	DeclarationDate string  `json:"declaration_date"` // YYYY-MM-DD
+	ExDividendDate  string  `json:"ex_dividend_date"` // Shares bought on or after this date don't receive the payment
+	RecordDate      string  `json:"record_date"`
+	PayDate         string  `json:"pay_date"`
+}
+
+// DividendsResponse represents the response from Polygon dividends endpoint, newest first
+type DividendsResponse struct {
+	Ticker    string     `json:"ticker"`
+	Results   []Dividend `json:"results"`
+	Status    string     `json:"status"`
+	RequestID string     `json:"request_id"`
+	Source    string     `json:"source,omitempty"` // Provider that served the data
+}
+
+// Split represents a stock split: SplitTo new shares for every SplitFrom old ones
+type Split struct {
+	Ticker        string  `json:"ticker"`
+	ExecutionDate string  `json:"execution_date"` // YYYY-MM-DD, the first day shares trade split-adjusted
+	SplitFrom     float64 `json:"split_from"`
+	SplitTo       float64 `json:"split_to"`
+}
+
+// Ratio is the number of shares held after the split for each share held before it
+func (s Split) Ratio() float64 {
+	if s.SplitFrom == 0 {
+		return 1
+	}
+	return s.SplitTo / s.SplitFrom
+}
+
+// SplitsResponse represents the response from Polygon splits endpoint, newest first
+type SplitsResponse struct {
+	Ticker    string  `json:"ticker"`
+	Results   []Split `json:"results"`
+	Status    string  `json:"status"`
+	RequestID string  `json:"request_id"`
+	Source    string  `json:"source,omitempty"` // Provider that served the data
+}
+
+// NewsPublisher identifies the outlet an article came from
+type NewsPublisher struct {
+	Name        string `json:"name"`
+	HomepageURL string `json:"homepage_url,omitempty"`
+	LogoURL     string `json:"logo_url,omitempty"`
+	FaviconURL  string `json:"favicon_url,omitempty"`
+}
+
+// NewsArticle represents a single article from Polygon news endpoint
+type NewsArticle struct {
+	ID           string        `json:"id"`
+	Publisher    NewsPublisher `json:"publisher"`
+	Title        string        `json:"title"`
+	Author       string        `json:"author,omitempty"`
+	PublishedUTC string        `json:"published_utc"` // RFC 3339
+	ArticleURL   string        `json:"article_url"`
+	Tickers      []string      `json:"tickers"`
+	ImageURL     string        `json:"image_url,omitempty"`
+	Description  string        `json:"description,omitempty"`
+	Keywords     []string      `json:"keywords,omitempty"`
+}
+
+// NewsParams selects a page of articles about one ticker, newest first
+type NewsParams struct {
+	Ticker string
+	Limit  int    // Page size, at most 1000 (default 10)
+	Cursor string // Continuation cursor from a previous page
+}
+
+// NewsResponse represents the full API response from Polygon news endpoint
+type NewsResponse struct {
+	Results   []NewsArticle `json:"results"`
+	Status    string        `json:"status"`
+	RequestID string        `json:"request_id"`
+	Count     int           `json:"count"`
+	NextURL   string        `json:"next_url"`
+}
+
+// NewsPage is one page of articles
+type NewsPage struct {
+	Results    []NewsArticle `json:"results"`
+	NextCursor string        `json:"next_cursor,omitempty"` // Empty on the last page
+	Source     string        `json:"source,omitempty"`      // Provider that served the page
 }
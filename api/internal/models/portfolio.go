@@ -14,12 +14,13 @@ type Stock struct {
 
 // Database model
 type Portfolio struct {
-	ID        string    `json:"id"`
-	UserID    string    `json:"user_id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Stocks    []Stock   `json:"stocks"`
+	ID              string    `json:"id"`
+	UserID          string    `json:"user_id"`
+	Name            string    `json:"name"`
+	CostBasisMethod string    `json:"cost_basis_method"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	Stocks          []Stock   `json:"stocks"`
 }
 
 type DisplayStock struct {
@@ -39,17 +40,20 @@ type CreatePortfolioRequest struct {
 type CreateStockRequest struct {
 	Ticker string  `json:"ticker" validate:"required,min=1,max=10"` // Changed from Name to Ticker
 	Shares float64 `json:"shares" validate:"required,min=0"`
+	Price  float64 `json:"price,omitempty" validate:"omitempty,min=0"` // Optional cost per share for the opening buy
 }
 
 // UpdatePortfolioRequest model
 type UpdatePortfolioRequest struct {
-	Name string `json:"name" validate:"required,min=1,max=100"`
+	Name            string  `json:"name" validate:"omitempty,min=1,max=100"`
+	CostBasisMethod *string `json:"cost_basis_method,omitempty" validate:"omitempty,oneof=fifo lifo average_cost specific_lot"` // Optional field for partial updates
 }
 
 // UpdateStockRequest model
 type UpdateStockRequest struct {
 	Ticker *string  `json:"ticker,omitempty" validate:"omitempty,min=1,max=10"` // Optional field for partial updates
 	Shares *float64 `json:"shares,omitempty" validate:"omitempty,min=0"`        // Optional field for partial updates
+	Price  *float64 `json:"price,omitempty" validate:"omitempty,min=0"`         // Optional price for the adjusting transaction
 }
 
 // MoveStockRequest model
@@ -1,5 +1,10 @@
 package models
 
+import (
+	"strings"
+	"time"
+)
+
 type Securities struct {
 	Ticker          string  `json:"ticker"`           // Required - cannot be null
 	Name            string  `json:"name"`             // Required - cannot be null
@@ -14,3 +19,57 @@ type Securities struct {
 	ShareClassFigi  *string `json:"share_class_figi"` // Optional - can be null
 	LastUpdatedUtc  *string `json:"last_updated_utc"` // Optional - can be null
 }
+
+// SecurityMatch is a search result annotated with how it matched the query
+type SecurityMatch struct {
+	Securities
+	MatchType string `json:"match_type"` // exact_ticker, ticker_prefix, name, fuzzy_ticker, or fuzzy_name
+}
+
+// SecurityFilter narrows securities search results. Each non-empty field is a
+// set of accepted values compared case-insensitively; empty fields match anything.
+type SecurityFilter struct {
+	Type            []string `json:"type,omitempty"`
+	PrimaryExchange []string `json:"primary_exchange,omitempty"`
+	Market          []string `json:"market,omitempty"`
+	Locale          []string `json:"locale,omitempty"`
+	CurrencyName    []string `json:"currency_name,omitempty"`
+}
+
+// Matches reports whether a security passes every set field of the filter
+func (f SecurityFilter) Matches(s Securities) bool {
+	return matchesAny(f.Type, s.Type) &&
+		matchesAny(f.PrimaryExchange, s.PrimaryExchange) &&
+		matchesAny(f.Market, s.Market) &&
+		matchesAny(f.Locale, s.Locale) &&
+		matchesAny(f.CurrencyName, s.CurrencyName)
+}
+
+func matchesAny(accepted []string, value *string) bool {
+	if len(accepted) == 0 {
+		return true
+	}
+	if value == nil {
+		return false
+	}
+	for _, a := range accepted {
+		if strings.EqualFold(a, *value) {
+			return true
+		}
+	}
+	return false
+}
+
+// SecuritiesSyncRun records one pass of the securities master sync
+type SecuritiesSyncRun struct {
+	ID          string     `json:"id"`
+	Markets     []string   `json:"markets"`
+	Status      string     `json:"status"` // running, succeeded, or failed
+	Fetched     int        `json:"fetched"`
+	Inserted    int        `json:"inserted"`
+	Updated     int        `json:"updated"`
+	Deactivated int        `json:"deactivated"`
+	Error       *string    `json:"error,omitempty"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Split adjustment statuses
+const (
+	SplitAdjustmentApplied   = "applied"   // A split transaction was added to the ledger
+	SplitAdjustmentPending   = "pending"   // Waiting for the user to apply or dismiss it
+	SplitAdjustmentDismissed = "dismissed" // The user said the position already reflects the split
+	SplitAdjustmentRecorded  = "recorded"  // The ledger already had a split on that date
+)
+
+// Database model
+// SplitAdjustment is a stock split found for a position that was held when it executed.
+type SplitAdjustment struct {
+	ID            string     `json:"id" db:"id"`
+	StockID       string     `json:"stock_id" db:"stock_id"`
+	Ticker        string     `json:"ticker" db:"ticker"`
+	ExecutionDate time.Time  `json:"execution_date" db:"execution_date"`
+	SplitFrom     float64    `json:"split_from" db:"split_from"`
+	SplitTo       float64    `json:"split_to" db:"split_to"`
+	Status        string     `json:"status" db:"status"`
+	Reason        *string    `json:"reason" db:"reason"` // Why a pending adjustment wasn't applied automatically
+	TransactionID *string    `json:"transaction_id" db:"transaction_id"`
+	DetectedAt    time.Time  `json:"detected_at" db:"detected_at"`
+	ResolvedAt    *time.Time `json:"resolved_at" db:"resolved_at"`
+}
+
+// SplitAdjustmentRun summarizes one pass of the split adjustment job
+type SplitAdjustmentRun struct {
+	Tickers int `json:"tickers"` // Held tickers checked
+	Failed  int `json:"failed"`  // Tickers whose splits couldn't be fetched
+	Applied int `json:"applied"`
+	Pending int `json:"pending"`
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// TransactionType identifies what kind of ledger entry a transaction is
+type TransactionType string
+
+const (
+	TransactionBuy              TransactionType = "buy"
+	TransactionSell             TransactionType = "sell"
+	TransactionDividendReinvest TransactionType = "dividend_reinvest"
+	TransactionSplit            TransactionType = "split"
+)
+
+// Valid reports whether the type is one the ledger understands
+func (t TransactionType) Valid() bool {
+	switch t {
+	case TransactionBuy, TransactionSell, TransactionDividendReinvest, TransactionSplit:
+		return true
+	}
+	return false
+}
+
+// Database model
+// A position's share count is derived from its transactions, oldest first.
+type Transaction struct {
+	ID         string          `json:"id" db:"id"`
+	StockID    string          `json:"stock_id" db:"stock_id"`
+	Ticker     string          `json:"ticker" db:"ticker"`
+	Type       TransactionType `json:"type" db:"type"`
+	Shares     float64         `json:"shares" db:"shares"`           // Always positive; direction comes from Type
+	Price      float64         `json:"price" db:"price"`             // Per-share price, 0 for splits
+	Fees       float64         `json:"fees" db:"fees"`               // Commissions and other costs
+	SplitRatio *float64        `json:"split_ratio" db:"split_ratio"` // New shares per old share, splits only
+	TradeDate  time.Time       `json:"trade_date" db:"trade_date"`
+	Notes      *string         `json:"notes" db:"notes"`
+	// LotSelections names the lots a sell closes under specific-lot identification
+	LotSelections []LotSelection `json:"lot_selections,omitempty" db:"lot_selections"`
+	CreatedAt     time.Time      `json:"created_at" db:"created_at"`
+}
+
+// LotSelection assigns part of a sell to the lot opened by an earlier transaction
+type LotSelection struct {
+	TransactionID string  `json:"transaction_id"`
+	Shares        float64 `json:"shares"`
+}
+
+// CreateTransactionRequest model
+type CreateTransactionRequest struct {
+	Ticker     string          `json:"ticker" validate:"required,min=1,max=10"`
+	Type       TransactionType `json:"type" validate:"required"`
+	Shares     float64         `json:"shares" validate:"min=0"`
+	Price      float64         `json:"price" validate:"min=0"`
+	Fees       float64         `json:"fees" validate:"min=0"`
+	SplitRatio *float64        `json:"split_ratio,omitempty" validate:"omitempty,gt=0"`
+	TradeDate  string          `json:"trade_date" validate:"required"` // YYYY-MM-DD
+	Notes      *string         `json:"notes,omitempty"`
+	// Optional, sells only: lots to close under specific-lot identification
+	LotSelections []LotSelection `json:"lot_selections,omitempty"`
+}
+
+// TransactionResponse pairs a recorded transaction with the position it updated
+type TransactionResponse struct {
+	Transaction Transaction `json:"transaction"`
+	Stock       Stock       `json:"stock"`
+}
+
+// ErrOversold is returned when a ledger sells more shares than it holds at that point in time
+var ErrOversold = errors.New("sell exceeds shares held")
+
+// shareEpsilon absorbs floating point noise when checking for a negative balance
+const shareEpsilon = 1e-9
+
+// DeriveShares replays transactions in order and returns the resulting share count.
+// Transactions must already be sorted by trade date.
+func DeriveShares(transactions []Transaction) (float64, error) {
+	var shares float64
+	for _, t := range transactions {
+		switch t.Type {
+		case TransactionBuy, TransactionDividendReinvest:
+			shares += t.Shares
+		case TransactionSell:
+			shares -= t.Shares
+			if shares < -shareEpsilon {
+				return 0, ErrOversold
+			}
+		case TransactionSplit:
+			if t.SplitRatio != nil {
+				shares *= *t.SplitRatio
+			}
+		}
+	}
+	if shares < shareEpsilon {
+		shares = 0
+	}
+	return shares, nil
+}
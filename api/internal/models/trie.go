@@ -1,6 +1,7 @@
 package models
 
 import (
+	"slices"
 	"strings"
 )
 
@@ -92,10 +93,16 @@ func (t *Trie) collectSecurities(node *TrieNode, results *[]Securities) {
 		*results = append(*results, node.Securities...)
 	}
 
-	// Recursively collect from children
+	// Recursively collect from children in key order so results are deterministic
 	if node.Children != nil {
-		for _, child := range node.Children {
-			t.collectSecurities(child, results)
+		keys := make([]rune, 0, len(node.Children))
+		for char := range node.Children {
+			keys = append(keys, char)
+		}
+		slices.Sort(keys)
+
+		for _, char := range keys {
+			t.collectSecurities(node.Children[char], results)
 		}
 	}
 }
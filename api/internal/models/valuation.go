@@ -0,0 +1,26 @@
+package models
+
+// HoldingValuation is the market value of one ticker in a portfolio.
+// Price fields are nil when no quote could be found.
+type HoldingValuation struct {
+	Ticker        string   `json:"ticker"`
+	Shares        float64  `json:"shares"`
+	Price         *float64 `json:"price"`
+	PreviousClose *float64 `json:"previous_close"`
+	MarketValue   float64  `json:"market_value"`
+	DayChange     float64  `json:"day_change"`
+	DayChangePct  float64  `json:"day_change_pct"`
+	Weight        float64  `json:"weight"` // Percent of the portfolio's priced market value
+}
+
+// PortfolioValuation prices every holding in a portfolio as of one trading day
+type PortfolioValuation struct {
+	PortfolioID       string             `json:"portfolio_id"`
+	AsOf              string             `json:"as_of"` // Trading day of the prices, YYYY-MM-DD
+	Holdings          []HoldingValuation `json:"holdings"`
+	TotalMarketValue  float64            `json:"total_market_value"`
+	TotalDayChange    float64            `json:"total_day_change"`
+	TotalDayChangePct float64            `json:"total_day_change_pct"`
+	Unpriced          []string           `json:"unpriced"`
+	DayChangeMissing  []string           `json:"day_change_missing"` // Priced tickers with no previous close, left out of the day change
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/cole-zoom/dUW-app/api/internal/models"
+)
+
+// Financial statement timeframes
+const (
+	TimeframeQuarterly = "quarterly"
+	TimeframeAnnual    = "annual"
+)
+
+// GetFundamentals returns a ticker's recent financial statements with valuation,
+// margin, and leverage ratios derived from the latest of them and the previous close.
+// With quarterly statements, income figures are summed over the trailing four quarters
+// so ratios are annual either way; balance sheet figures come from the latest period.
+func (s *StockService) GetFundamentals(ctx context.Context, ticker, timeframe string) (*models.Fundamentals, error) {
+	financials, err := s.stockAPIClient.GetFinancials(ctx, ticker, timeframe)
+	if err != nil {
+		return nil, err
+	}
+
+	fundamentals := &models.Fundamentals{
+		Ticker:     financials.Ticker,
+		Timeframe:  timeframe,
+		Statements: financials.Results,
+		Source:     financials.Source,
+	}
+	if len(financials.Results) == 0 {
+		return fundamentals, nil
+	}
+	latest := financials.Results[0]
+	fundamentals.PeriodEnd = latest.EndDate
+
+	// Income over the trailing year: the latest annual report, or the last four quarters
+	periods := financials.Results[:1]
+	if timeframe == TimeframeQuarterly {
+		periods = trailingYear(financials.Results)
+		fundamentals.Trailing = periods != nil
+	}
+	income := func(item func(models.IncomeStatement) *models.FinancialValue) *float64 {
+		if periods == nil {
+			return nil
+		}
+		total := 0.0
+		for _, period := range periods {
+			value := item(period.Financials.IncomeStatement)
+			if value == nil {
+				return nil
+			}
+			total += float64(*value)
+		}
+		return &total
+	}
+
+	revenue := income(func(i models.IncomeStatement) *models.FinancialValue { return i.Revenues })
+	grossProfit := income(func(i models.IncomeStatement) *models.FinancialValue { return i.GrossProfit })
+	operatingIncome := income(func(i models.IncomeStatement) *models.FinancialValue { return i.OperatingIncomeLoss })
+	netIncome := income(func(i models.IncomeStatement) *models.FinancialValue { return i.NetIncomeLoss })
+	parentIncome := income(func(i models.IncomeStatement) *models.FinancialValue {
+		if i.NetIncomeLossAttributableToParent != nil {
+			return i.NetIncomeLossAttributableToParent
+		}
+		return i.NetIncomeLoss
+	})
+	eps := income(func(i models.IncomeStatement) *models.FinancialValue {
+		if i.DilutedEarningsPerShare != nil {
+			return i.DilutedEarningsPerShare
+		}
+		return i.BasicEarningsPerShare
+	})
+
+	balance := latest.Financials.BalanceSheet
+	equity := balance.EquityAttributableToParent.Float()
+	if equity == nil {
+		equity = balance.Equity.Float()
+	}
+
+	ratios := &fundamentals.Ratios
+	ratios.GrossMargin = percentOf(grossProfit, revenue)
+	ratios.OperatingMargin = percentOf(operatingIncome, revenue)
+	ratios.NetMargin = percentOf(netIncome, revenue)
+	if equity != nil && *equity > 0 {
+		ratios.ReturnOnEquity = percentOf(parentIncome, equity)
+		ratios.DebtToEquity = ratio(balance.LongTermDebt.Float(), equity)
+		ratios.LiabilitiesToEquity = ratio(balance.Liabilities.Float(), equity)
+	}
+
+	// Valuation ratios need a price; without one the rest are still useful
+	prevClose, err := s.stockAPIClient.GetPreviousClose(ctx, ticker)
+	if err != nil || len(prevClose.Results) == 0 {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		log.Printf("GetFundamentals - No previous close for %s: %v", ticker, err)
+		return fundamentals, nil
+	}
+	bar := prevClose.Results[0]
+	price := bar.Close
+	fundamentals.Price = &price
+	fundamentals.PriceDate = time.UnixMilli(int64(bar.Timestamp)).UTC().Format("2006-01-02")
+
+	if eps != nil && *eps > 0 {
+		ratios.PriceToEarnings = ratio(&price, eps)
+	}
+	if equity != nil && *equity > 0 {
+		details, err := s.stockAPIClient.GetTickerDetails(ctx, ticker)
+		if err != nil {
+			log.Printf("GetFundamentals - Failed to get details for %s: %v", ticker, err)
+		} else if shares := sharesOutstanding(details); shares > 0 {
+			marketCap := price * shares
+			ratios.PriceToBook = ratio(&marketCap, equity)
+		}
+	}
+
+	return fundamentals, nil
+}
+
+// trailingYear returns the latest four quarterly reports if they cover one
+// contiguous year, or nil when quarters are missing
+func trailingYear(reports []models.FinancialReport) []models.FinancialReport {
+	if len(reports) < 4 {
+		return nil
+	}
+	end, err := time.Parse("2006-01-02", reports[0].EndDate)
+	if err != nil {
+		return nil
+	}
+	start, err := time.Parse("2006-01-02", reports[3].StartDate)
+	if err != nil {
+		return nil
+	}
+	// Fiscal quarters don't always line up with calendar months, so allow a little slack
+	if days := end.Sub(start).Hours() / 24; days < 350 || days > 380 {
+		return nil
+	}
+	return reports[:4]
+}
+
+// sharesOutstanding prefers the count across all share classes, which is what the
+// balance sheet's equity belongs to
+func sharesOutstanding(details *models.TickerDetails) float64 {
+	if details.WeightedSharesOutstanding > 0 {
+		return float64(details.WeightedSharesOutstanding)
+	}
+	return float64(details.ShareClassSharesOutstanding)
+}
+
+// ratio divides a by b, or returns nil when either is missing or b is zero
+func ratio(a, b *float64) *float64 {
+	if a == nil || b == nil || *b == 0 {
+		return nil
+	}
+	r := *a / *b
+	return &r
+}
+
+// percentOf is ratio as a percentage
+func percentOf(a, b *float64) *float64 {
+	r := ratio(a, b)
+	if r != nil {
+		*r *= 100
+	}
+	return r
+}
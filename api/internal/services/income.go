@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cole-zoom/dUW-app/api/internal/market"
+	"github.com/cole-zoom/dUW-app/api/internal/models"
+)
+
+// incomeMonths is how far ahead ProjectIncome looks
+const incomeMonths = 12
+
+// dividendPayment is one cash payment per share, dated by when it is paid
+type dividendPayment struct {
+	date      time.Time
+	amount    float64
+	currency  string
+	regular   bool // Specials and capital gains distributions don't recur
+	frequency int  // As reported by the provider
+}
+
+// ProjectIncome projects the next 12 months of dividend cash flow for a portfolio's
+// holdings, by month, along with trailing yield and yield on cost per holding.
+// costBasis is the total cost of the open shares of each ticker (upper case); tickers
+// without one get no yield on cost. Amounts are kept in the currency they are paid
+// in, so a portfolio with foreign holdings has more than one total.
+func (s *StockService) ProjectIncome(ctx context.Context, portfolioID string, stocks []models.Stock, costBasis map[string]float64) (*models.PortfolioIncome, error) {
+	// Current prices for trailing yield; this also combines duplicate tickers
+	valuation, err := s.ValueHoldings(ctx, portfolioID, stocks)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().In(market.Location())
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, market.Location())
+	firstMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, market.Location())
+	until := firstMonth.AddDate(0, incomeMonths, 0)
+
+	income := &models.PortfolioIncome{
+		PortfolioID: portfolioID,
+		AsOf:        from.Format("2006-01-02"),
+		Months:      make([]models.MonthlyIncome, incomeMonths),
+		Totals:      map[string]float64{},
+		Holdings:    []models.HoldingIncome{},
+		Unavailable: []string{},
+	}
+	for i := range income.Months {
+		income.Months[i] = models.MonthlyIncome{Month: firstMonth.AddDate(0, i, 0).Format("2006-01"), Amounts: map[string]float64{}}
+	}
+
+	for _, holding := range valuation.Holdings {
+		dividends, err := s.stockAPIClient.GetDividends(ctx, holding.Ticker)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			log.Printf("ProjectIncome - Failed to get dividends for %s: %v", holding.Ticker, err)
+			income.Unavailable = append(income.Unavailable, holding.Ticker)
+			continue
+		}
+
+		result := models.HoldingIncome{Ticker: holding.Ticker, Shares: holding.Shares}
+		if details, err := s.stockAPIClient.GetTickerDetails(ctx, holding.Ticker); err != nil {
+			log.Printf("ProjectIncome - Failed to get details for %s: %v", holding.Ticker, err)
+		} else {
+			result.ListingCurrency = strings.ToUpper(details.CurrencyName)
+		}
+
+		history := dividendPayments(dividends.Results)
+		for _, payment := range history {
+			if !payment.date.Before(from.AddDate(-1, 0, 0)) && payment.date.Before(from) {
+				result.TrailingDividend += payment.amount
+			}
+		}
+
+		var projected []dividendPayment
+		projected, result.Frequency = projectDividends(dividends.Results, from, until)
+		for _, payment := range projected {
+			result.ProjectedDividend += payment.amount
+			cash := payment.amount * holding.Shares
+			month := (payment.date.Year()-firstMonth.Year())*12 + int(payment.date.Month()-firstMonth.Month())
+			income.Months[month].Amounts[payment.currency] += cash
+			income.Totals[payment.currency] += cash
+		}
+		result.ProjectedIncome = result.ProjectedDividend * holding.Shares
+
+		// The dividend currency is that of the latest payment; fall back to the listing's
+		result.Currency = result.ListingCurrency
+		if len(history) > 0 && history[len(history)-1].currency != "" {
+			result.Currency = history[len(history)-1].currency
+		}
+
+		// Prices and cost are in the listing currency, so yields only make sense when it matches
+		if result.Currency == result.ListingCurrency || result.ListingCurrency == "" {
+			if holding.Price != nil && *holding.Price > 0 {
+				yield := result.TrailingDividend / *holding.Price * 100
+				result.TrailingYield = &yield
+			}
+			if cost := costBasis[holding.Ticker]; cost > 0 {
+				yieldOnCost := result.ProjectedIncome / cost * 100
+				result.YieldOnCost = &yieldOnCost
+			}
+		}
+
+		income.Holdings = append(income.Holdings, result)
+	}
+
+	return income, nil
+}
+
+// projectDividends returns the payments per share expected in [from, until) and the
+// regular payment frequency. Dividends already declared count as announced; after
+// the last regular one, its amount is repeated at its frequency. A regular dividend
+// that has been skipped twice is treated as suspended.
+func projectDividends(dividends []models.Dividend, from, until time.Time) ([]dividendPayment, int) {
+	var projected, regular []dividendPayment
+	for _, payment := range dividendPayments(dividends) {
+		if !payment.date.Before(from) && payment.date.Before(until) {
+			projected = append(projected, payment)
+		}
+		if payment.regular {
+			regular = append(regular, payment)
+		}
+	}
+	if len(regular) == 0 {
+		return projected, 0
+	}
+	latest := regular[len(regular)-1]
+
+	frequency := latest.frequency
+	if frequency != 1 && frequency != 2 && frequency != 4 && frequency != 12 {
+		frequency = inferFrequency(regular, latest.date)
+	}
+	interval := 12 / frequency
+	if latest.date.Before(from.AddDate(0, -2*interval, 0)) {
+		return projected, 0
+	}
+
+	for step := 1; ; step++ {
+		next := latest.date.AddDate(0, step*interval, 0)
+		if !next.Before(until) {
+			break
+		}
+		if !next.Before(from) {
+			projected = append(projected, dividendPayment{date: next, amount: latest.amount, currency: latest.currency})
+		}
+	}
+	return projected, frequency
+}
+
+// dividendPayments dates each dividend by its pay date, or its ex-dividend date when
+// no pay date is known, sorted oldest first. Dividends without either are dropped.
+func dividendPayments(dividends []models.Dividend) []dividendPayment {
+	payments := make([]dividendPayment, 0, len(dividends))
+	for _, d := range dividends {
+		date := d.PayDate
+		if date == "" {
+			date = d.ExDividendDate
+		}
+		parsed, err := time.ParseInLocation("2006-01-02", date, market.Location())
+		if err != nil {
+			continue
+		}
+		payments = append(payments, dividendPayment{
+			date:      parsed,
+			amount:    d.CashAmount,
+			currency:  strings.ToUpper(d.Currency),
+			regular:   d.DividendType == "" || d.DividendType == "CD",
+			frequency: d.Frequency,
+		})
+	}
+	slices.SortFunc(payments, func(a, b dividendPayment) int { return a.date.Compare(b.date) })
+	return payments
+}
+
+// inferFrequency estimates payments per year, when the provider doesn't report a
+// usable one, from how many regular dividends were paid in the year up to the latest
+func inferFrequency(regular []dividendPayment, latest time.Time) int {
+	count := 0
+	for _, payment := range regular {
+		if payment.date.After(latest.AddDate(-1, 0, 0)) {
+			count++
+		}
+	}
+	switch {
+	case count >= 10:
+		return 12
+	case count >= 3:
+		return 4
+	case count == 2:
+		return 2
+	default:
+		return 1
+	}
+}
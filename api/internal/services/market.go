@@ -0,0 +1,134 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/cole-zoom/dUW-app/api/internal/market"
+	"github.com/cole-zoom/dUW-app/api/internal/models"
+)
+
+// upcomingHolidayDays is how far ahead MarketStatus lists holidays and early closes
+const upcomingHolidayDays = 90
+
+// RefreshMarketCalendar loads the provider's upcoming holidays into the trading
+// calendar so unscheduled closures are honored. Until it succeeds, the calendar
+// follows the regular NYSE holiday rules.
+func (s *StockService) RefreshMarketCalendar(ctx context.Context) error {
+	resp, err := s.stockAPIClient.GetMarketHolidays(ctx)
+	if err != nil {
+		return err
+	}
+
+	var holidays []market.Holiday
+	for _, h := range resp.Results {
+		if !strings.EqualFold(h.Exchange, "NYSE") {
+			continue
+		}
+		holiday := market.Holiday{Date: h.Date, Name: h.Name}
+		if h.Status == "early-close" {
+			closes, err := time.Parse(time.RFC3339, h.Close)
+			if err != nil {
+				log.Printf("RefreshMarketCalendar - Skipping early close on %s: %v", h.Date, err)
+				continue
+			}
+			holiday.Close = closes
+		}
+		holidays = append(holidays, holiday)
+	}
+	market.SetHolidays(holidays)
+	return nil
+}
+
+// MarketStatus reports whether US equities are trading now, along with today's
+// session and upcoming holidays. The provider's live status is preferred; when
+// it can't be reached, the status is worked out from the trading calendar.
+func (s *StockService) MarketStatus(ctx context.Context) (*models.MarketStatus, error) {
+	if err := s.RefreshMarketCalendar(ctx); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		log.Printf("MarketStatus - Failed to refresh market holidays: %v", err)
+	}
+
+	now := time.Now().In(market.Location())
+	status := &models.MarketStatus{
+		AsOf:      now.Format(time.RFC3339),
+		NextOpen:  market.NextOpen(now).Format(time.RFC3339),
+		NextClose: market.NextClose(now).Format(time.RFC3339),
+		Upcoming:  []models.MarketCalendarDay{},
+		Source:    "calendar",
+	}
+
+	phase := market.PhaseAt(now)
+	if upstream, err := s.stockAPIClient.GetMarketStatus(ctx); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		log.Printf("MarketStatus - Failed to get market status, using the trading calendar: %v", err)
+	} else {
+		phase = providerPhase(upstream, phase)
+		status.Exchanges = upstream.Exchanges
+		status.Source = cmp.Or(upstream.Source, "provider")
+	}
+	status.Status = string(phase)
+	status.IsOpen = phase == market.PhaseOpen
+
+	if session, ok := market.SessionOn(now); ok {
+		status.Session = &models.MarketSession{
+			Date:       session.Date(),
+			Open:       session.Open.Format(time.RFC3339),
+			Close:      session.Close.Format(time.RFC3339),
+			EarlyClose: session.EarlyClose,
+		}
+	}
+	if holiday, ok := market.HolidayOn(now); ok {
+		day := calendarDay(holiday)
+		status.Holiday = &day
+	}
+	for _, holiday := range market.Holidays(now.AddDate(0, 0, 1), now.AddDate(0, 0, upcomingHolidayDays)) {
+		status.Upcoming = append(status.Upcoming, calendarDay(holiday))
+	}
+
+	return status, nil
+}
+
+// SessionRange returns the first and last dates (YYYY-MM-DD) of the last n trading
+// sessions, so a "last N days" chart covers N sessions however many weekends and
+// holidays fall in between. Today counts once its session has opened.
+func (s *StockService) SessionRange(ctx context.Context, n int) (string, string) {
+	if err := s.RefreshMarketCalendar(ctx); err != nil {
+		log.Printf("SessionRange - Failed to refresh market holidays: %v", err)
+	}
+	sessions := market.LastSessions(time.Now(), n)
+	return sessions[0].Date(), sessions[len(sessions)-1].Date()
+}
+
+// providerPhase maps a provider's market status onto a phase, keeping the
+// calendar's phase when the provider doesn't say which extended session it is
+func providerPhase(upstream *models.MarketStatusResponse, calendarPhase market.Phase) market.Phase {
+	switch {
+	case upstream.Market == "open":
+		return market.PhaseOpen
+	case upstream.Market == "closed":
+		return market.PhaseClosed
+	case upstream.EarlyHours:
+		return market.PhasePreMarket
+	case upstream.AfterHours:
+		return market.PhaseAfterHours
+	default:
+		return calendarPhase
+	}
+}
+
+func calendarDay(holiday market.Holiday) models.MarketCalendarDay {
+	day := models.MarketCalendarDay{Date: holiday.Date, Name: holiday.Name, Status: "closed"}
+	if !holiday.Closed() {
+		day.Status = "early-close"
+		day.Close = holiday.Close.In(market.Location()).Format(time.RFC3339)
+	}
+	return day
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"encoding/base64"
+	"errors"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/cole-zoom/dUW-app/api/internal/clients"
+	"github.com/cole-zoom/dUW-app/api/internal/models"
+)
+
+// portfolioNewsDepth is how many of each holding's latest articles the portfolio feed draws on
+const portfolioNewsDepth = 50
+
+// ErrInvalidNewsCursor is returned for a portfolio news cursor that can't be decoded
+var ErrInvalidNewsCursor = errors.New("invalid cursor")
+
+// GetNews retrieves a page of news articles about a ticker, newest first.
+func (s *StockService) GetNews(ctx context.Context, params models.NewsParams) (*models.NewsPage, error) {
+	return s.stockAPIClient.GetNews(ctx, params)
+}
+
+// PortfolioNews merges the latest articles about every holding into one feed, newest
+// first, listing an article once even when it mentions several holdings. The feed
+// is built from each holding's most recent articles, so paging reaches back as far
+// as those go. Cursors mark the last article of the previous page, so articles
+// published since don't shift later pages.
+func (s *StockService) PortfolioNews(ctx context.Context, portfolioID string, stocks []models.Stock, limit int, cursor string) (*models.PortfolioNews, error) {
+	var after *models.NewsArticle
+	if cursor != "" {
+		decoded, err := decodeNewsCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = &decoded
+	}
+
+	// One request fans out to a call per holding; let single-ticker lookups go first
+	ctx = clients.WithPriority(ctx, clients.PriorityBulk)
+
+	feed := &models.PortfolioNews{
+		PortfolioID: portfolioID,
+		Results:     []models.NewsArticle{},
+		Unavailable: []string{},
+	}
+
+	seenTickers := make(map[string]bool)
+	seenIDs := make(map[string]bool)
+	seenURLs := make(map[string]bool)
+	var articles []models.NewsArticle
+	for _, stock := range stocks {
+		ticker := strings.ToUpper(stock.Ticker)
+		if seenTickers[ticker] {
+			continue
+		}
+		seenTickers[ticker] = true
+
+		page, err := s.stockAPIClient.GetNews(ctx, models.NewsParams{Ticker: ticker, Limit: portfolioNewsDepth})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			log.Printf("PortfolioNews - Failed to get news for %s: %v", ticker, err)
+			feed.Unavailable = append(feed.Unavailable, ticker)
+			continue
+		}
+
+		// Syndicated stories can reach us under different IDs but the same link
+		for _, article := range page.Results {
+			if seenIDs[article.ID] || (article.ArticleURL != "" && seenURLs[article.ArticleURL]) {
+				continue
+			}
+			seenIDs[article.ID] = true
+			if article.ArticleURL != "" {
+				seenURLs[article.ArticleURL] = true
+			}
+			articles = append(articles, article)
+		}
+	}
+
+	slices.SortFunc(articles, compareNews)
+
+	start := 0
+	if after != nil {
+		start, _ = slices.BinarySearchFunc(articles, *after, compareNews)
+		if start < len(articles) && compareNews(articles[start], *after) == 0 {
+			start++
+		}
+	}
+	end := min(start+limit, len(articles))
+	feed.Results = append(feed.Results, articles[start:end]...)
+	if end < len(articles) && end > start {
+		feed.NextCursor = encodeNewsCursor(articles[end-1])
+	}
+	return feed, nil
+}
+
+// compareNews orders articles newest first, breaking ties by ID so the order is stable
+func compareNews(a, b models.NewsArticle) int {
+	return cmp.Or(strings.Compare(b.PublishedUTC, a.PublishedUTC), strings.Compare(a.ID, b.ID))
+}
+
+// encodeNewsCursor makes an opaque cursor pointing just past an article
+func encodeNewsCursor(last models.NewsArticle) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(last.PublishedUTC + "|" + last.ID))
+}
+
+// decodeNewsCursor returns the sort position an encodeNewsCursor cursor points past
+func decodeNewsCursor(cursor string) (models.NewsArticle, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return models.NewsArticle{}, ErrInvalidNewsCursor
+	}
+	published, id, ok := strings.Cut(string(data), "|")
+	if !ok || published == "" {
+		return models.NewsArticle{}, ErrInvalidNewsCursor
+	}
+	return models.NewsArticle{PublishedUTC: published, ID: id}, nil
+}
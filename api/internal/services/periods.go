@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cole-zoom/dUW-app/api/internal/market"
+)
+
+// AggregatePeriods are the named chart ranges ResolvePeriod understands
+var AggregatePeriods = []string{"1D", "1W", "1M", "6M", "1Y", "YTD", "5Y", "MAX"}
+
+// ErrUnknownPeriod is returned by ResolvePeriod for a name not in AggregatePeriods
+var ErrUnknownPeriod = errors.New("unknown period")
+
+// AggregateRange is the dates and bar size a named period resolves to
+type AggregateRange struct {
+	From       string // YYYY-MM-DD
+	To         string // YYYY-MM-DD
+	Multiplier string
+	Timespan   string
+}
+
+// ResolvePeriod turns a named chart period into dates and a bar size that keeps
+// the chart to a few hundred bars. 1D and 1W cover the last 1 and 5 trading
+// sessions; longer periods count back calendar time from the latest session and
+// start on the first session after that.
+func (s *StockService) ResolvePeriod(ctx context.Context, period string) (AggregateRange, error) {
+	period = strings.ToUpper(period)
+	if !slices.Contains(AggregatePeriods, period) {
+		return AggregateRange{}, ErrUnknownPeriod
+	}
+
+	// Session ranges come from the trading calendar, which may refresh from the provider
+	switch period {
+	case "1D":
+		from, to := s.SessionRange(ctx, 1)
+		return AggregateRange{From: from, To: to, Multiplier: "5", Timespan: "minute"}, nil
+	case "1W":
+		from, to := s.SessionRange(ctx, 5)
+		return AggregateRange{From: from, To: to, Multiplier: "30", Timespan: "minute"}, nil
+	}
+
+	_, to := s.SessionRange(ctx, 1)
+	latest, err := time.ParseInLocation("2006-01-02", to, market.Location())
+	if err != nil {
+		return AggregateRange{}, err
+	}
+
+	var start time.Time
+	resolved := AggregateRange{To: to, Multiplier: "1", Timespan: "day"}
+	switch period {
+	case "1M":
+		start = monthsBefore(latest, 1).AddDate(0, 0, 1)
+	case "6M":
+		start = monthsBefore(latest, 6).AddDate(0, 0, 1)
+	case "1Y":
+		start = monthsBefore(latest, 12).AddDate(0, 0, 1)
+	case "YTD":
+		start = time.Date(latest.Year(), time.January, 1, 0, 0, 0, 0, market.Location())
+	case "5Y":
+		start = monthsBefore(latest, 60).AddDate(0, 0, 1)
+		resolved.Timespan = "week"
+	case "MAX":
+		// Providers return whatever history they have from here on
+		resolved.From = "1970-01-01"
+		resolved.Timespan = "month"
+		return resolved, nil
+	}
+
+	for !market.IsTradingDay(start) {
+		start = start.AddDate(0, 0, 1)
+	}
+	resolved.From = start.Format("2006-01-02")
+	return resolved, nil
+}
+
+// monthsBefore steps back n calendar months, clamping to the end of shorter months
+// (March 31 goes back to February 28 rather than rolling over into March)
+func monthsBefore(t time.Time, n int) time.Time {
+	first := time.Date(t.Year(), t.Month()-time.Month(n), 1, 0, 0, 0, 0, t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return time.Date(first.Year(), first.Month(), min(t.Day(), lastDay), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,327 @@
+// Package pnl computes cost basis and realized/unrealized gains from a
+// position's transaction ledger. It has no database or network dependencies.
+package pnl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/cole-zoom/dUW-app/api/internal/models"
+)
+
+// Method selects how sold shares are matched against open lots
+type Method string
+
+const (
+	FIFO        Method = "fifo"
+	LIFO        Method = "lifo"
+	AverageCost Method = "average_cost"
+	SpecificLot Method = "specific_lot"
+)
+
+// Valid reports whether the method is supported by the calculator
+func (m Method) Valid() bool {
+	switch m {
+	case FIFO, LIFO, AverageCost, SpecificLot:
+		return true
+	}
+	return false
+}
+
+var (
+	// ErrInsufficientShares is returned when a sell exceeds the shares in open lots
+	ErrInsufficientShares = errors.New("sell exceeds shares held")
+	// ErrInvalidLotSelection is returned when a specific-lot sell names lots that can't cover it
+	ErrInvalidLotSelection = errors.New("invalid lot selection")
+)
+
+// epsilon absorbs floating point noise when comparing share quantities
+const epsilon = 1e-9
+
+// Lot is an open tax lot created by a buy or dividend reinvestment
+type Lot struct {
+	TransactionID string    `json:"transaction_id"`
+	AcquiredOn    time.Time `json:"acquired_on"`
+	Shares        float64   `json:"shares"`
+	CostPerShare  float64   `json:"cost_per_share"` // Includes a pro-rata share of fees
+	CostBasis     float64   `json:"cost_basis"`
+}
+
+// Realization is the gain or loss booked by one sell transaction
+type Realization struct {
+	TransactionID string    `json:"transaction_id"`
+	Date          time.Time `json:"date"`
+	Shares        float64   `json:"shares"`
+	Proceeds      float64   `json:"proceeds"` // Net of fees
+	CostBasis     float64   `json:"cost_basis"`
+	Gain          float64   `json:"gain"`
+}
+
+// Position is the result of replaying one position's ledger
+type Position struct {
+	Ticker         string        `json:"ticker"`
+	Method         Method        `json:"method"`
+	Shares         float64       `json:"shares"`
+	CostBasis      float64       `json:"cost_basis"`
+	AverageCost    float64       `json:"average_cost"`
+	RealizedGain   float64       `json:"realized_gain"`
+	Realizations   []Realization `json:"realizations"`
+	OpenLots       []Lot         `json:"open_lots"`
+	MarketPrice    *float64      `json:"market_price"`
+	MarketValue    *float64      `json:"market_value"`
+	UnrealizedGain *float64      `json:"unrealized_gain"`
+	UnrealizedPct  *float64      `json:"unrealized_pct"`
+}
+
+// Calculate replays transactions (sorted oldest first) using the given method.
+// Sells under SpecificLot without lot selections fall back to FIFO.
+func Calculate(ticker string, transactions []models.Transaction, method Method) (*Position, error) {
+	if !method.Valid() {
+		return nil, fmt.Errorf("unknown cost basis method: %s", method)
+	}
+
+	p := &Position{
+		Ticker:       ticker,
+		Method:       method,
+		Realizations: []Realization{},
+		OpenLots:     []Lot{},
+	}
+
+	for _, t := range transactions {
+		switch t.Type {
+		case models.TransactionBuy, models.TransactionDividendReinvest:
+			p.addLot(t)
+		case models.TransactionSell:
+			if err := p.sell(t, method); err != nil {
+				return nil, fmt.Errorf("transaction %s on %s: %w", t.ID, t.TradeDate.Format("2006-01-02"), err)
+			}
+		case models.TransactionSplit:
+			if t.SplitRatio != nil {
+				p.split(*t.SplitRatio)
+			}
+		}
+	}
+
+	p.summarize()
+	return p, nil
+}
+
+// Mark values the open shares at the given market price
+func (p *Position) Mark(price float64) {
+	marketValue := p.Shares * price
+	unrealized := marketValue - p.CostBasis
+
+	p.MarketPrice = &price
+	p.MarketValue = &marketValue
+	p.UnrealizedGain = &unrealized
+	if p.CostBasis > 0 {
+		pct := unrealized / p.CostBasis * 100
+		p.UnrealizedPct = &pct
+	}
+}
+
+// addLot opens a lot, folding the transaction's fees into its cost
+func (p *Position) addLot(t models.Transaction) {
+	cost := t.Shares*t.Price + t.Fees
+	p.OpenLots = append(p.OpenLots, Lot{
+		TransactionID: t.ID,
+		AcquiredOn:    t.TradeDate,
+		Shares:        t.Shares,
+		CostPerShare:  cost / t.Shares,
+		CostBasis:     cost,
+	})
+}
+
+// sell relieves open lots for a sell transaction and books the realized gain
+func (p *Position) sell(t models.Transaction, method Method) error {
+	if t.Shares > p.openShares()+epsilon {
+		return ErrInsufficientShares
+	}
+
+	var costBasis float64
+	var err error
+	switch {
+	case method == AverageCost:
+		costBasis = p.relieveProRata(t.Shares)
+	case method == SpecificLot && len(t.LotSelections) > 0:
+		costBasis, err = p.relieveSelected(t.Shares, t.LotSelections)
+	case method == LIFO:
+		costBasis = p.relieveOrdered(t.Shares, true)
+	default:
+		costBasis = p.relieveOrdered(t.Shares, false)
+	}
+	if err != nil {
+		return err
+	}
+
+	proceeds := t.Shares*t.Price - t.Fees
+	p.Realizations = append(p.Realizations, Realization{
+		TransactionID: t.ID,
+		Date:          t.TradeDate,
+		Shares:        t.Shares,
+		Proceeds:      proceeds,
+		CostBasis:     costBasis,
+		Gain:          proceeds - costBasis,
+	})
+	p.dropEmptyLots()
+	return nil
+}
+
+// relieveOrdered consumes lots oldest first, or newest first when newestFirst is set
+func (p *Position) relieveOrdered(shares float64, newestFirst bool) float64 {
+	var costBasis float64
+	remaining := shares
+	for i := range p.OpenLots {
+		if remaining <= epsilon {
+			break
+		}
+		idx := i
+		if newestFirst {
+			idx = len(p.OpenLots) - 1 - i
+		}
+		take := math.Min(remaining, p.OpenLots[idx].Shares)
+		costBasis += p.consume(idx, take)
+		remaining -= take
+	}
+	return costBasis
+}
+
+// relieveProRata consumes the same fraction of every lot, which prices the sale at average cost
+func (p *Position) relieveProRata(shares float64) float64 {
+	fraction := shares / p.openShares()
+	var costBasis float64
+	for i := range p.OpenLots {
+		costBasis += p.consume(i, p.OpenLots[i].Shares*fraction)
+	}
+	return costBasis
+}
+
+// relieveSelected consumes exactly the lots named by a specific-lot sell
+func (p *Position) relieveSelected(shares float64, selections []models.LotSelection) (float64, error) {
+	var selected float64
+	for _, sel := range selections {
+		selected += sel.Shares
+	}
+	if math.Abs(selected-shares) > epsilon {
+		return 0, fmt.Errorf("%w: selected %g shares but sold %g", ErrInvalidLotSelection, selected, shares)
+	}
+
+	// Validate every selection before mutating any lot. A lot may be named more than
+	// once, so it's the total taken from each that has to fit.
+	indexes := make([]int, len(selections))
+	taken := make(map[int]float64, len(selections))
+	for i, sel := range selections {
+		idx := p.lotIndex(sel.TransactionID)
+		if idx < 0 {
+			return 0, fmt.Errorf("%w: lot %s is not open", ErrInvalidLotSelection, sel.TransactionID)
+		}
+		if sel.Shares <= 0 {
+			return 0, fmt.Errorf("%w: lot %s selected with %g shares", ErrInvalidLotSelection, sel.TransactionID, sel.Shares)
+		}
+		taken[idx] += sel.Shares
+		if taken[idx] > p.OpenLots[idx].Shares+epsilon {
+			return 0, fmt.Errorf("%w: selected %g shares of lot %s, which holds %g", ErrInvalidLotSelection, taken[idx], sel.TransactionID, p.OpenLots[idx].Shares)
+		}
+		indexes[i] = idx
+	}
+
+	var costBasis float64
+	for i, sel := range selections {
+		// Only floating point noise can exceed the lot now
+		costBasis += p.consume(indexes[i], math.Min(sel.Shares, p.OpenLots[indexes[i]].Shares))
+	}
+	return costBasis, nil
+}
+
+// consume removes shares from a lot and returns the cost basis relieved
+func (p *Position) consume(idx int, shares float64) float64 {
+	lot := &p.OpenLots[idx]
+	cost := shares * lot.CostPerShare
+	lot.Shares -= shares
+	lot.CostBasis -= cost
+	return cost
+}
+
+// split rescales every open lot; total cost is unchanged
+func (p *Position) split(ratio float64) {
+	for i := range p.OpenLots {
+		p.OpenLots[i].Shares *= ratio
+		p.OpenLots[i].CostPerShare /= ratio
+	}
+}
+
+func (p *Position) lotIndex(transactionID string) int {
+	for i, lot := range p.OpenLots {
+		if lot.TransactionID == transactionID {
+			return i
+		}
+	}
+	return -1
+}
+
+func (p *Position) openShares() float64 {
+	var shares float64
+	for _, lot := range p.OpenLots {
+		shares += lot.Shares
+	}
+	return shares
+}
+
+func (p *Position) dropEmptyLots() {
+	open := p.OpenLots[:0]
+	for _, lot := range p.OpenLots {
+		if lot.Shares > epsilon {
+			open = append(open, lot)
+		}
+	}
+	p.OpenLots = open
+}
+
+// summarize totals the open lots and realized gains
+func (p *Position) summarize() {
+	p.Shares, p.CostBasis, p.RealizedGain = 0, 0, 0
+	for _, lot := range p.OpenLots {
+		p.Shares += lot.Shares
+		p.CostBasis += lot.CostBasis
+	}
+	for _, r := range p.Realizations {
+		p.RealizedGain += r.Gain
+	}
+	if p.Shares > epsilon {
+		p.AverageCost = p.CostBasis / p.Shares
+	}
+}
+
+// Summary aggregates positions across a portfolio
+type Summary struct {
+	Method         Method      `json:"method"`
+	Positions      []*Position `json:"positions"`
+	CostBasis      float64     `json:"cost_basis"`
+	MarketValue    float64     `json:"market_value"`
+	RealizedGain   float64     `json:"realized_gain"`
+	UnrealizedGain float64     `json:"unrealized_gain"`
+	TotalGain      float64     `json:"total_gain"`
+	Unpriced       []string    `json:"unpriced"` // Tickers without a market price; excluded from market totals
+}
+
+// Summarize totals a set of calculated (and optionally marked) positions
+func Summarize(method Method, positions []*Position) Summary {
+	s := Summary{Method: method, Positions: positions, Unpriced: []string{}}
+	for _, p := range positions {
+		s.RealizedGain += p.RealizedGain
+		if p.Shares <= epsilon {
+			continue
+		}
+		if p.MarketValue == nil {
+			s.Unpriced = append(s.Unpriced, p.Ticker)
+			continue
+		}
+		s.CostBasis += p.CostBasis
+		s.MarketValue += *p.MarketValue
+		s.UnrealizedGain += *p.UnrealizedGain
+	}
+	s.TotalGain = s.RealizedGain + s.UnrealizedGain
+	return s
+}
@@ -0,0 +1,166 @@
+package pnl
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cole-zoom/dUW-app/api/internal/models"
+)
+
+func day(n int) time.Time {
+	return time.Date(2024, time.January, n, 0, 0, 0, 0, time.UTC)
+}
+
+func buy(id string, date time.Time, shares, price float64) models.Transaction {
+	return models.Transaction{ID: id, Type: models.TransactionBuy, Shares: shares, Price: price, TradeDate: date}
+}
+
+func sell(id string, date time.Time, shares, price float64, selections ...models.LotSelection) models.Transaction {
+	return models.Transaction{ID: id, Type: models.TransactionSell, Shares: shares, Price: price, TradeDate: date, LotSelections: selections}
+}
+
+func split(date time.Time, ratio float64) models.Transaction {
+	return models.Transaction{ID: "split", Type: models.TransactionSplit, SplitRatio: &ratio, TradeDate: date}
+}
+
+func TestCalculate(t *testing.T) {
+	// Two lots: 10 shares at $10, then 10 at $20
+	lots := []models.Transaction{buy("b1", day(1), 10, 10), buy("b2", day(2), 10, 20)}
+	ledger := func(more ...models.Transaction) []models.Transaction {
+		return append(append([]models.Transaction{}, lots...), more...)
+	}
+
+	tests := []struct {
+		name         string
+		method       Method
+		transactions []models.Transaction
+		err          error
+		shares       float64
+		costBasis    float64
+		realized     float64
+	}{
+		{
+			name:         "fifo sells the oldest lot first",
+			method:       FIFO,
+			transactions: ledger(sell("s1", day(3), 15, 30)),
+			shares:       5, costBasis: 100, realized: 450 - 200,
+		},
+		{
+			name:         "lifo sells the newest lot first",
+			method:       LIFO,
+			transactions: ledger(sell("s1", day(3), 15, 30)),
+			shares:       5, costBasis: 50, realized: 450 - 250,
+		},
+		{
+			name:         "average cost prices the sale at the mean",
+			method:       AverageCost,
+			transactions: ledger(sell("s1", day(3), 15, 30)),
+			shares:       5, costBasis: 75, realized: 450 - 225,
+		},
+		{
+			name:   "specific lot sells the chosen shares",
+			method: SpecificLot,
+			transactions: ledger(sell("s1", day(3), 15, 30,
+				models.LotSelection{TransactionID: "b1", Shares: 5},
+				models.LotSelection{TransactionID: "b2", Shares: 10},
+			)),
+			shares: 5, costBasis: 50, realized: 450 - 250,
+		},
+		{
+			name:         "specific lot without selections falls back to fifo",
+			method:       SpecificLot,
+			transactions: ledger(sell("s1", day(3), 15, 30)),
+			shares:       5, costBasis: 100, realized: 450 - 200,
+		},
+		{
+			name:   "specific lot rejects selections that don't add up to the sale",
+			method: SpecificLot,
+			transactions: ledger(sell("s1", day(3), 15, 30,
+				models.LotSelection{TransactionID: "b1", Shares: 5},
+			)),
+			err: ErrInvalidLotSelection,
+		},
+		{
+			name:   "specific lot rejects a lot chosen twice for more than it holds",
+			method: SpecificLot,
+			transactions: ledger(sell("s1", day(3), 15, 30,
+				models.LotSelection{TransactionID: "b1", Shares: 8},
+				models.LotSelection{TransactionID: "b1", Shares: 7},
+			)),
+			err: ErrInvalidLotSelection,
+		},
+		{
+			name:   "specific lot rejects a lot that isn't open",
+			method: SpecificLot,
+			transactions: ledger(sell("s1", day(3), 5, 30,
+				models.LotSelection{TransactionID: "missing", Shares: 5},
+			)),
+			err: ErrInvalidLotSelection,
+		},
+		{
+			name:         "oversold",
+			method:       FIFO,
+			transactions: ledger(sell("s1", day(3), 25, 30)),
+			err:          ErrInsufficientShares,
+		},
+		{
+			name:         "oversold after an earlier sale",
+			method:       AverageCost,
+			transactions: ledger(sell("s1", day(3), 15, 30), sell("s2", day(4), 6, 30)),
+			err:          ErrInsufficientShares,
+		},
+		{
+			name:         "splits rescale lots without changing their cost",
+			method:       FIFO,
+			transactions: ledger(split(day(3), 2), sell("s1", day(4), 30, 15)),
+			shares:       10, costBasis: 100, realized: 450 - 200,
+		},
+		{
+			name:         "fees are part of cost and come out of proceeds",
+			method:       FIFO,
+			transactions: []models.Transaction{{ID: "b1", Type: models.TransactionBuy, Shares: 10, Price: 10, Fees: 10, TradeDate: day(1)}, {ID: "s1", Type: models.TransactionSell, Shares: 5, Price: 20, Fees: 5, TradeDate: day(2)}},
+			shares:       5, costBasis: 55, realized: 95 - 55,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			position, err := Calculate("TEST", tt.transactions, tt.method)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("err = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, check := range []struct {
+				field     string
+				got, want float64
+			}{
+				{"shares", position.Shares, tt.shares},
+				{"cost basis", position.CostBasis, tt.costBasis},
+				{"realized gain", position.RealizedGain, tt.realized},
+			} {
+				if math.Abs(check.got-check.want) > 1e-6 {
+					t.Errorf("%s = %g, want %g", check.field, check.got, check.want)
+				}
+			}
+		})
+	}
+}
+
+func TestMark(t *testing.T) {
+	position, err := Calculate("TEST", []models.Transaction{buy("b1", day(1), 10, 10)}, FIFO)
+	if err != nil {
+		t.Fatal(err)
+	}
+	position.Mark(15)
+	if *position.MarketValue != 150 || *position.UnrealizedGain != 50 || *position.UnrealizedPct != 50 {
+		t.Errorf("marked at 15: value %g, gain %g, pct %g; want 150, 50, 50", *position.MarketValue, *position.UnrealizedGain, *position.UnrealizedPct)
+	}
+}
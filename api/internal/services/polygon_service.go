@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/cole-zoom/dUW-app/api/internal/clients"
 	"github.com/cole-zoom/dUW-app/api/internal/models"
@@ -19,6 +20,15 @@ func (s *StockService) GetSuggestedStocks(ctx context.Context, query string) ([]
 	return s.stockAPIClient.GetSuggestedStocks(ctx, query)
 }
 
+// GetSuggestedStocksPage retrieves one page of suggestions and the cursor for the next page.
+func (s *StockService) GetSuggestedStocksPage(ctx context.Context, query, cursor string) ([]models.DisplayStock, string, error) {
+	page, err := s.stockAPIClient.ListTickersPage(ctx, models.TickerListParams{Search: query, Market: "stocks", Cursor: cursor})
+	if err != nil {
+		return nil, "", err
+	}
+	return models.ToDisplayStocks(page.Results), page.NextCursor, nil
+}
+
 // GetAggregates retrieves historical OHLC data for a ticker.
 func (s *StockService) GetAggregates(ctx context.Context, ticker, multiplier, timespan, from, to string) (*models.AggregatesResponse, error) {
 	return s.stockAPIClient.GetAggregates(ctx, ticker, multiplier, timespan, from, to)
@@ -33,3 +43,25 @@ func (s *StockService) GetTickerDetails(ctx context.Context, ticker string) (*mo
 func (s *StockService) GetPreviousClose(ctx context.Context, ticker string) (*models.PreviousCloseResponse, error) {
 	return s.stockAPIClient.GetPreviousClose(ctx, ticker)
 }
+
+// GetDividends retrieves a ticker's cash dividend history, newest first.
+func (s *StockService) GetDividends(ctx context.Context, ticker string) (*models.DividendsResponse, error) {
+	return s.stockAPIClient.GetDividends(ctx, ticker)
+}
+
+// GetSplits retrieves a ticker's stock split history, newest first.
+func (s *StockService) GetSplits(ctx context.Context, ticker string) (*models.SplitsResponse, error) {
+	return s.stockAPIClient.GetSplits(ctx, ticker)
+}
+
+// GetLatestPrice returns the most recent close for a ticker, used to mark positions to market.
+func (s *StockService) GetLatestPrice(ctx context.Context, ticker string) (float64, error) {
+	prevClose, err := s.stockAPIClient.GetPreviousClose(ctx, ticker)
+	if err != nil {
+		return 0, err
+	}
+	if len(prevClose.Results) == 0 {
+		return 0, fmt.Errorf("no previous close available for %s", ticker)
+	}
+	return prevClose.Results[0].Close, nil
+}
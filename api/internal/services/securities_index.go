@@ -0,0 +1,198 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cole-zoom/dUW-app/api/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SecuritiesSnapshot is an immutable, fully built view of the active securities.
+// Readers share a snapshot without locking; refreshes build a new one and swap it in.
+type SecuritiesSnapshot struct {
+	Securities []models.Securities // Sorted by ticker
+	Trie       *models.Trie
+	BuiltAt    time.Time
+	BuildTime  time.Duration
+
+	// TriePayload is the gzip-compressed JSON of the trie endpoint's response
+	TriePayload []byte
+	// ETag is a strong validator for TriePayload
+	ETag string
+
+	search      *searchIndex
+	contentHash string // Hash of the securities data, used to skip no-op swaps
+}
+
+// SecuritiesIndex holds the process-wide securities snapshot
+type SecuritiesIndex struct {
+	db        *pgxpool.Pool
+	current   atomic.Pointer[SecuritiesSnapshot]
+	refreshMu sync.Mutex // Serializes rebuilds so concurrent refreshes don't race each other
+}
+
+// NewSecuritiesIndex creates an empty index; call Refresh to build it
+func NewSecuritiesIndex(db *pgxpool.Pool) *SecuritiesIndex {
+	return &SecuritiesIndex{db: db}
+}
+
+// Snapshot returns the current snapshot, or nil if the index has never been built
+func (i *SecuritiesIndex) Snapshot() *SecuritiesSnapshot {
+	return i.current.Load()
+}
+
+// Get returns the current snapshot, building it first if necessary
+func (i *SecuritiesIndex) Get(ctx context.Context) (*SecuritiesSnapshot, error) {
+	if snapshot := i.current.Load(); snapshot != nil {
+		return snapshot, nil
+	}
+	return i.Refresh(ctx)
+}
+
+// Refresh reloads securities from the database and atomically swaps in a new
+// snapshot. If the data is unchanged the existing snapshot (and its ETag) is kept.
+func (i *SecuritiesIndex) Refresh(ctx context.Context) (*SecuritiesSnapshot, error) {
+	i.refreshMu.Lock()
+	defer i.refreshMu.Unlock()
+
+	startTime := time.Now()
+
+	securities, err := i.fetchActiveSecurities(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(securities)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash securities: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	contentHash := hex.EncodeToString(sum[:])
+
+	if current := i.current.Load(); current != nil && current.contentHash == contentHash {
+		log.Printf("SecuritiesIndex - Refresh found no changes across %d securities", len(securities))
+		return current, nil
+	}
+
+	trie := models.NewTrie()
+	for _, security := range securities {
+		trie.Insert(security)
+	}
+	buildTime := time.Since(startTime)
+
+	payload, err := compressTrieResponse(models.CompressedTrieResponse{
+		Trie:      trie,
+		Count:     len(securities),
+		Version:   contentHash[:12],
+		BuildTime: buildTime.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	payloadSum := sha256.Sum256(payload)
+
+	snapshot := &SecuritiesSnapshot{
+		Securities:  securities,
+		Trie:        trie,
+		BuiltAt:     time.Now(),
+		BuildTime:   buildTime,
+		TriePayload: payload,
+		ETag:        `"` + hex.EncodeToString(payloadSum[:16]) + `"`,
+		search:      buildSearchIndex(securities),
+		contentHash: contentHash,
+	}
+	i.current.Store(snapshot)
+
+	log.Printf("SecuritiesIndex - Built index with %d securities in %v (%d bytes compressed)", len(securities), buildTime, len(payload))
+	return snapshot, nil
+}
+
+// Schedule refreshes the index every interval until ctx is cancelled
+func (i *SecuritiesIndex) Schedule(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := i.Refresh(ctx); err != nil {
+				log.Printf("SecuritiesIndex - Scheduled refresh failed: %v", err)
+			}
+		}
+	}
+}
+
+// compressTrieResponse encodes and gzips the trie response once so every request can reuse it
+func compressTrieResponse(response models.CompressedTrieResponse) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(gz).Encode(response); err != nil {
+		return nil, fmt.Errorf("failed to encode trie: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress trie: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// fetchActiveSecurities retrieves all active securities from the database
+func (i *SecuritiesIndex) fetchActiveSecurities(ctx context.Context) ([]models.Securities, error) {
+	query := `
+		SELECT ticker, name, market, locale, primary_exchange, type, active,
+		       currency_name, cik, composite_figi, share_class_figi,
+		       to_char(last_updated_utc AT TIME ZONE 'UTC', 'YYYY-MM-DD HH24:MI:SS.MS+00') as last_updated_utc
+		FROM securities
+		WHERE active = true
+		ORDER BY ticker ASC
+	`
+
+	rows, err := i.db.Query(ctx, query)
+	if err != nil {
+		log.Printf("Database query failed: %v", err)
+		return nil, fmt.Errorf("failed to query securities: %w", err)
+	}
+	defer rows.Close()
+
+	var securities []models.Securities
+	for rows.Next() {
+		var security models.Securities
+		err := rows.Scan(
+			&security.Ticker,
+			&security.Name,
+			&security.Market,
+			&security.Locale,
+			&security.PrimaryExchange,
+			&security.Type,
+			&security.Active,
+			&security.CurrencyName,
+			&security.Cik,
+			&security.CompositeFigi,
+			&security.ShareClassFigi,
+			&security.LastUpdatedUtc,
+		)
+		if err != nil {
+			log.Printf("Failed to scan security row: %v", err)
+			return nil, fmt.Errorf("failed to scan security: %w", err)
+		}
+		securities = append(securities, security)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("Row iteration error: %v", err)
+		return nil, fmt.Errorf("row iteration failed: %w", err)
+	}
+
+	return securities, nil
+}
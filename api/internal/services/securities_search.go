@@ -0,0 +1,249 @@
+package services
+
+import (
+	"cmp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/cole-zoom/dUW-app/api/internal/models"
+)
+
+// Match ranks, best first. Lower ranks always sort ahead of higher ones.
+const (
+	rankExactTicker = iota
+	rankTickerPrefix
+	rankName
+	rankFuzzyTicker
+	rankFuzzyName
+)
+
+var matchTypes = map[int]string{
+	rankExactTicker:  "exact_ticker",
+	rankTickerPrefix: "ticker_prefix",
+	rankName:         "name",
+	rankFuzzyTicker:  "fuzzy_ticker",
+	rankFuzzyName:    "fuzzy_name",
+}
+
+// minFuzzyTokenLength keeps short name tokens ("co", "inc") from fuzzily matching everything
+const minFuzzyTokenLength = 4
+
+// searchIndex supports company-name and typo-tolerant lookups alongside the ticker Trie
+type searchIndex struct {
+	securities []models.Securities
+	tokens     []string         // Sorted unique lowercase name tokens
+	postings   map[string][]int // Token -> indexes into securities
+}
+
+type candidate struct {
+	index    int
+	rank     int
+	distance int
+}
+
+// buildSearchIndex tokenizes every security name
+func buildSearchIndex(securities []models.Securities) *searchIndex {
+	idx := &searchIndex{
+		securities: securities,
+		postings:   make(map[string][]int),
+	}
+	for i, security := range securities {
+		for _, token := range tokenize(security.Name) {
+			postings := idx.postings[token]
+			if len(postings) == 0 || postings[len(postings)-1] != i {
+				idx.postings[token] = append(postings, i)
+			}
+		}
+	}
+	for token := range idx.postings {
+		idx.tokens = append(idx.tokens, token)
+	}
+	sort.Strings(idx.tokens)
+	return idx
+}
+
+// Search ranks securities against a query: exact ticker matches first, then
+// ticker prefixes, then company-name matches, then ticker and name matches
+// within a small edit distance. Ties break on edit distance and then ticker,
+// so the same query always returns the same order. Securities that don't pass
+// the filter are never considered.
+func (s *SecuritiesSnapshot) Search(query string, filter models.SecurityFilter) []models.SecurityMatch {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return []models.SecurityMatch{}
+	}
+
+	best := make(map[int]candidate)
+	consider := func(c candidate) {
+		if !filter.Matches(s.search.securities[c.index]) {
+			return
+		}
+		if existing, ok := best[c.index]; !ok || c.rank < existing.rank ||
+			(c.rank == existing.rank && c.distance < existing.distance) {
+			best[c.index] = c
+		}
+	}
+
+	ticker := strings.ToUpper(query)
+	for i, security := range s.search.securities {
+		if !filter.Matches(security) {
+			continue
+		}
+		securityTicker := strings.ToUpper(security.Ticker)
+		switch {
+		case securityTicker == ticker:
+			consider(candidate{index: i, rank: rankExactTicker})
+		case strings.HasPrefix(securityTicker, ticker):
+			consider(candidate{index: i, rank: rankTickerPrefix})
+		case !strings.ContainsRune(ticker, ' ') && len(ticker) >= 3:
+			maxDistance := fuzzyBudget(len(ticker))
+			if d := boundedDistance(ticker, securityTicker, maxDistance); d <= maxDistance {
+				consider(candidate{index: i, rank: rankFuzzyTicker, distance: d})
+			}
+		}
+	}
+
+	for i, distance := range s.search.matchName(tokenize(query)) {
+		rank := rankName
+		if distance > 0 {
+			rank = rankFuzzyName
+		}
+		consider(candidate{index: i, rank: rank, distance: distance})
+	}
+
+	ranked := make([]candidate, 0, len(best))
+	for _, c := range best {
+		ranked = append(ranked, c)
+	}
+	slices.SortFunc(ranked, func(a, b candidate) int {
+		return cmp.Or(
+			cmp.Compare(a.rank, b.rank),
+			cmp.Compare(a.distance, b.distance),
+			cmp.Compare(len(s.search.securities[a.index].Ticker), len(s.search.securities[b.index].Ticker)),
+			strings.Compare(s.search.securities[a.index].Ticker, s.search.securities[b.index].Ticker),
+		)
+	})
+
+	matches := make([]models.SecurityMatch, 0, len(ranked))
+	for _, c := range ranked {
+		matches = append(matches, models.SecurityMatch{
+			Securities: s.search.securities[c.index],
+			MatchType:  matchTypes[c.rank],
+		})
+	}
+	return matches
+}
+
+// matchName returns securities whose name matches every query token, either as
+// a token prefix (distance 0) or within the fuzzy budget, with the summed distance.
+func (idx *searchIndex) matchName(queryTokens []string) map[int]int {
+	if len(queryTokens) == 0 {
+		return nil
+	}
+
+	var matched map[int]int
+	for _, queryToken := range queryTokens {
+		tokenMatches := make(map[int]int)
+		record := func(token string, distance int) {
+			for _, i := range idx.postings[token] {
+				if existing, ok := tokenMatches[i]; !ok || distance < existing {
+					tokenMatches[i] = distance
+				}
+			}
+		}
+
+		// Prefix matches come from a contiguous run of the sorted tokens
+		start := sort.SearchStrings(idx.tokens, queryToken)
+		for j := start; j < len(idx.tokens) && strings.HasPrefix(idx.tokens[j], queryToken); j++ {
+			record(idx.tokens[j], 0)
+		}
+
+		if len(queryToken) >= minFuzzyTokenLength {
+			maxDistance := fuzzyBudget(len(queryToken))
+			for _, token := range idx.tokens {
+				if len(token) < minFuzzyTokenLength {
+					continue
+				}
+				if d := boundedDistance(queryToken, token, maxDistance); d > 0 && d <= maxDistance {
+					record(token, d)
+				}
+			}
+		}
+
+		// Every query token has to match some name token
+		if matched == nil {
+			matched = tokenMatches
+			continue
+		}
+		for i, distance := range matched {
+			if tokenDistance, ok := tokenMatches[i]; ok {
+				matched[i] = distance + tokenDistance
+			} else {
+				delete(matched, i)
+			}
+		}
+	}
+	return matched
+}
+
+// fuzzyBudget allows one typo in short strings and two in longer ones
+func fuzzyBudget(length int) int {
+	if length <= 4 {
+		return 1
+	}
+	return 2
+}
+
+// tokenize lowercases a name and splits it on anything that isn't a letter or digit
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// boundedDistance returns the optimal string alignment distance between a and b
+// (Levenshtein plus adjacent transpositions), or maxDistance+1 as soon as the
+// distance is known to exceed maxDistance.
+func boundedDistance(a, b string, maxDistance int) int {
+	ar, br := []rune(a), []rune(b)
+	if abs(len(ar)-len(br)) > maxDistance {
+		return maxDistance + 1
+	}
+
+	prevPrev := make([]int, len(br)+1)
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ar[i-1] == br[j-2] && ar[i-2] == br[j-1] {
+				curr[j] = min(curr[j], prevPrev[j-2]+1)
+			}
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > maxDistance {
+			return maxDistance + 1
+		}
+		prevPrev, prev, curr = prev, curr, prevPrev
+	}
+	return prev[len(br)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/cole-zoom/dUW-app/api/internal/clients"
+	"github.com/cole-zoom/dUW-app/api/internal/market"
+	"github.com/cole-zoom/dUW-app/api/internal/models"
+)
+
+// maxSessionLookback bounds how many calendar days we walk back looking for a
+// trading session with data (long weekends plus a holiday fit comfortably).
+const maxSessionLookback = 7
+
+// ValueHoldings prices every holding using Polygon's grouped daily bars, so the
+// whole portfolio costs two upstream requests (latest and prior session) instead
+// of one per ticker. Tickers missing from the grouped bars, or every ticker when
+// the provider has no grouped bars, fall back to their own recent daily bars.
+// Holdings without a previous close are listed in DayChangeMissing, since the day
+// change total leaves them out.
+func (s *StockService) ValueHoldings(ctx context.Context, portfolioID string, stocks []models.Stock) (*models.PortfolioValuation, error) {
+	// One request can fan out to a call per holding; let single-ticker lookups go first
+	ctx = clients.WithPriority(ctx, clients.PriorityBulk)
+
+	valuation := &models.PortfolioValuation{
+		PortfolioID:      portfolioID,
+		Holdings:         []models.HoldingValuation{},
+		Unpriced:         []string{},
+		DayChangeMissing: []string{},
+	}
+
+	// Combine duplicate rows for the same ticker, keeping portfolio order
+	var tickers []string
+	shares := make(map[string]float64)
+	for _, stock := range stocks {
+		ticker := strings.ToUpper(stock.Ticker)
+		if stock.Shares <= 0 {
+			continue
+		}
+		if _, seen := shares[ticker]; !seen {
+			tickers = append(tickers, ticker)
+		}
+		shares[ticker] += stock.Shares
+	}
+	if len(tickers) == 0 {
+		return valuation, nil
+	}
+
+	// Providers without grouped bars leave these empty, so every holding is priced individually
+	latest, asOf, err := s.latestGroupedDaily(ctx, time.Now().In(market.Location()))
+	switch {
+	case errors.Is(err, clients.ErrUnsupported):
+		valuation.AsOf = market.Today()
+	case err != nil:
+		return nil, err
+	default:
+		valuation.AsOf = asOf.Format("2006-01-02")
+	}
+	var previous map[string]models.AggregateBar
+	priceDay := time.Now().In(market.Location())
+	if latest != nil {
+		priceDay = asOf
+		previous, _, err = s.latestGroupedDaily(ctx, asOf.AddDate(0, 0, -1))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var previousTotal float64
+	for _, ticker := range tickers {
+		holding := models.HoldingValuation{Ticker: ticker, Shares: shares[ticker]}
+
+		if bar, ok := latest[ticker]; ok {
+			holding.Price = &bar.Close
+			if prevBar, ok := previous[ticker]; ok {
+				holding.PreviousClose = &prevBar.Close
+			}
+		} else {
+			// Not in the grouped bars (e.g. OTC or just listed); price it on its own
+			price, previousClose, err := s.dailyCloses(ctx, ticker, priceDay)
+			if err != nil {
+				log.Printf("ValueHoldings - Failed to price %s: %v", ticker, err)
+				valuation.Unpriced = append(valuation.Unpriced, ticker)
+				valuation.Holdings = append(valuation.Holdings, holding)
+				continue
+			}
+			holding.Price = &price
+			holding.PreviousClose = previousClose
+		}
+
+		holding.MarketValue = holding.Shares * *holding.Price
+		if holding.PreviousClose != nil && *holding.PreviousClose > 0 {
+			holding.DayChange = holding.Shares * (*holding.Price - *holding.PreviousClose)
+			holding.DayChangePct = (*holding.Price - *holding.PreviousClose) / *holding.PreviousClose * 100
+		} else {
+			valuation.DayChangeMissing = append(valuation.DayChangeMissing, ticker)
+		}
+
+		valuation.TotalMarketValue += holding.MarketValue
+		valuation.TotalDayChange += holding.DayChange
+		previousTotal += holding.MarketValue - holding.DayChange
+		valuation.Holdings = append(valuation.Holdings, holding)
+	}
+
+	if valuation.TotalMarketValue > 0 {
+		for i := range valuation.Holdings {
+			valuation.Holdings[i].Weight = valuation.Holdings[i].MarketValue / valuation.TotalMarketValue * 100
+		}
+	}
+	if previousTotal > 0 {
+		valuation.TotalDayChangePct = valuation.TotalDayChange / previousTotal * 100
+	}
+
+	return valuation, nil
+}
+
+// dailyCloses returns a ticker's latest daily close on or before day and, when there
+// is one, the close of the session before it. If the daily bars can't be had, the
+// price comes from the ticker's previous close alone.
+func (s *StockService) dailyCloses(ctx context.Context, ticker string, day time.Time) (float64, *float64, error) {
+	from := day.AddDate(0, 0, -2*maxSessionLookback).Format("2006-01-02")
+	bars, err := s.stockAPIClient.GetAggregates(ctx, ticker, "1", "day", from, day.Format("2006-01-02"))
+	if err != nil || len(bars.Results) == 0 {
+		if ctx.Err() != nil {
+			return 0, nil, ctx.Err()
+		}
+		log.Printf("ValueHoldings - No daily bars for %s, using its previous close: %v", ticker, err)
+		price, err := s.GetLatestPrice(ctx, ticker)
+		return price, nil, err
+	}
+
+	results := bars.Results
+	price := results[len(results)-1].Close
+	if len(results) < 2 {
+		return price, nil, nil
+	}
+	previous := results[len(results)-2].Close
+	return price, &previous, nil
+}
+
+// latestGroupedDaily walks back from the given day to the most recent session
+// with grouped bars and returns them keyed by ticker along with that session's date.
+// Weekends and exchange holidays are skipped without spending a request.
+func (s *StockService) latestGroupedDaily(ctx context.Context, from time.Time) (map[string]models.AggregateBar, time.Time, error) {
+	day := from
+	for i := 0; i < maxSessionLookback; i, day = i+1, day.AddDate(0, 0, -1) {
+		if !market.IsTradingDay(day) {
+			continue
+		}
+
+		grouped, err := s.stockAPIClient.GetGroupedDaily(ctx, day.Format("2006-01-02"))
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		if len(grouped.Results) == 0 {
+			continue // Unscheduled closure, or today's session hasn't closed yet
+		}
+
+		bars := make(map[string]models.AggregateBar, len(grouped.Results))
+		for _, bar := range grouped.Results {
+			bars[bar.Ticker] = bar
+		}
+		return bars, day, nil
+	}
+	return nil, time.Time{}, fmt.Errorf("no trading session found in the %d days before %s", maxSessionLookback, from.Format("2006-01-02"))
+}
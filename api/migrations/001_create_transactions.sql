@@ -0,0 +1,29 @@
+-- Transaction ledger for stock positions.
+-- stocks.shares is kept as a cached value derived from these rows.
+
+CREATE TABLE IF NOT EXISTS transactions (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    stock_id    UUID NOT NULL REFERENCES stocks(id) ON DELETE CASCADE,
+    type        TEXT NOT NULL CHECK (type IN ('buy', 'sell', 'dividend_reinvest', 'split')),
+    shares      NUMERIC NOT NULL DEFAULT 0 CHECK (shares >= 0),
+    price       NUMERIC NOT NULL DEFAULT 0 CHECK (price >= 0),
+    fees        NUMERIC NOT NULL DEFAULT 0 CHECK (fees >= 0),
+    split_ratio NUMERIC CHECK (split_ratio IS NULL OR split_ratio > 0),
+    trade_date  DATE NOT NULL,
+    notes       TEXT,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_stock_id_trade_date
+    ON transactions (stock_id, trade_date, created_at);
+
+-- Migrate every existing position into an opening buy so the ledger
+-- reproduces today's share counts. The original cost is unknown. The buy is
+-- dated today rather than when the position was created: the count already
+-- reflects any splits and corrections since then, so backdating it would have
+-- those splits applied a second time.
+INSERT INTO transactions (stock_id, type, shares, price, fees, trade_date, notes)
+SELECT s.id, 'buy', s.shares, 0, 0, CURRENT_DATE, 'Opening balance'
+FROM stocks s
+WHERE s.shares > 0
+  AND NOT EXISTS (SELECT 1 FROM transactions t WHERE t.stock_id = s.id);
@@ -0,0 +1,9 @@
+-- Per-portfolio cost basis method and specific-lot identification for sells.
+
+ALTER TABLE portfolios
+    ADD COLUMN IF NOT EXISTS cost_basis_method TEXT NOT NULL DEFAULT 'fifo'
+    CHECK (cost_basis_method IN ('fifo', 'lifo', 'average_cost', 'specific_lot'));
+
+-- JSON array of {"transaction_id": ..., "shares": ...} naming the lots a sell closes
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS lot_selections JSONB;
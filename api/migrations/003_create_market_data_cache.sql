@@ -0,0 +1,12 @@
+-- Optional persistent backend for the market data cache (CACHE_BACKEND=postgres).
+
+CREATE TABLE IF NOT EXISTS market_data_cache (
+    key        TEXT PRIMARY KEY,
+    value      BYTEA NOT NULL,
+    expires_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_market_data_cache_expires_at
+    ON market_data_cache (expires_at)
+    WHERE expires_at IS NOT NULL;
@@ -0,0 +1,21 @@
+-- Securities master sync from Polygon /v3/reference/tickers.
+
+-- Upserts key on the ticker
+CREATE UNIQUE INDEX IF NOT EXISTS idx_securities_ticker ON securities (ticker);
+
+-- Tickers not seen by a sync that covered their market are marked inactive
+ALTER TABLE securities
+    ADD COLUMN IF NOT EXISTS last_synced_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS securities_sync_runs (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    markets     TEXT[] NOT NULL,
+    status      TEXT NOT NULL CHECK (status IN ('running', 'succeeded', 'failed')),
+    fetched     INTEGER NOT NULL DEFAULT 0,
+    inserted    INTEGER NOT NULL DEFAULT 0,
+    updated     INTEGER NOT NULL DEFAULT 0,
+    deactivated INTEGER NOT NULL DEFAULT 0,
+    error       TEXT,
+    started_at  TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMPTZ
+);
@@ -0,0 +1,22 @@
+-- Stock splits detected for held positions by the split adjustment job.
+-- One row per position and split, so re-running the job never applies a split twice.
+
+CREATE TABLE IF NOT EXISTS split_adjustments (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    stock_id       UUID NOT NULL REFERENCES stocks(id) ON DELETE CASCADE,
+    execution_date DATE NOT NULL,
+    split_from     NUMERIC NOT NULL CHECK (split_from > 0),
+    split_to       NUMERIC NOT NULL CHECK (split_to > 0),
+    -- applied: split transaction recorded; pending: waiting for the user to confirm;
+    -- dismissed: the user declined it; recorded: the ledger already had the split
+    status         TEXT NOT NULL CHECK (status IN ('applied', 'pending', 'dismissed', 'recorded')),
+    reason         TEXT,
+    transaction_id UUID REFERENCES transactions(id) ON DELETE SET NULL,
+    detected_at    TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_at    TIMESTAMPTZ,
+    UNIQUE (stock_id, execution_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_split_adjustments_pending
+    ON split_adjustments (stock_id)
+    WHERE status = 'pending';
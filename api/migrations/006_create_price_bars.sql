@@ -0,0 +1,32 @@
+-- Price history: closed bars saved from market data responses so each is
+-- downloaded from the provider once.
+
+CREATE TABLE IF NOT EXISTS price_bars (
+    ticker     TEXT NOT NULL,
+    timespan   TEXT NOT NULL,
+    multiplier INTEGER NOT NULL,
+    timestamp  BIGINT NOT NULL, -- Start of the bar, Unix milliseconds
+    open       DOUBLE PRECISION NOT NULL,
+    high       DOUBLE PRECISION NOT NULL,
+    low        DOUBLE PRECISION NOT NULL,
+    close      DOUBLE PRECISION NOT NULL,
+    volume     DOUBLE PRECISION NOT NULL,
+    vwap       DOUBLE PRECISION NOT NULL DEFAULT 0,
+    trades     INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (ticker, timespan, multiplier, timestamp)
+);
+
+-- Dates whose bars are all in price_bars, so gaps with no trading aren't requested again.
+-- verified_at is when the bars were last checked against the ticker's splits.
+CREATE TABLE IF NOT EXISTS price_bar_ranges (
+    ticker      TEXT NOT NULL,
+    timespan    TEXT NOT NULL,
+    multiplier  INTEGER NOT NULL,
+    from_date   DATE NOT NULL,
+    to_date     DATE NOT NULL CHECK (to_date >= from_date),
+    verified_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (ticker, timespan, multiplier, from_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_bar_ranges_ticker
+    ON price_bar_ranges (ticker);
@@ -11,57 +11,6 @@ import { useStackAuth } from "./useStackAuth"
 
 const API_BASE_URL = process.env.NEXT_PUBLIC_API_URL || "http://localhost:8080"
 
-/**
- * Calculate date range and timespan based on selected period
- */
-function getDateRange(period: TimePeriod): {
-  from: string
-  to: string
-  timespan: string
-  multiplier: string
-} {
-  const to = new Date()
-  const from = new Date()
-
-  let timespan = 'day'
-  let multiplier = '1'
-
-  switch (period) {
-    case '1D':
-      from.setDate(from.getDate() - 1)
-      timespan = 'hour'
-      multiplier = '1'
-      break
-    case '1W':
-      from.setDate(from.getDate() - 7)
-      timespan = 'hour'
-      multiplier = '4'
-      break
-    case '1M':
-      from.setMonth(from.getMonth() - 1)
-      timespan = 'day'
-      multiplier = '1'
-      break
-    case '6M':
-      from.setMonth(from.getMonth() - 6)
-      timespan = 'day'
-      multiplier = '1'
-      break
-    case '1Y':
-      from.setFullYear(from.getFullYear() - 1)
-      timespan = 'week'
-      multiplier = '1'
-      break
-  }
-
-  return {
-    from: from.toISOString().split('T')[0],
-    to: to.toISOString().split('T')[0],
-    timespan,
-    multiplier,
-  }
-}
-
 /**
  * Hook for fetching stock data from Polygon.io API
  * Implements sequential fetching to respect rate limits (5 calls/min)
@@ -85,8 +34,8 @@ export function useStockData() {
     period: TimePeriod = '1M'
   ): Promise<AggregatesResponse | null> => {
     try {
-      const { from, to, timespan, multiplier } = getDateRange(period)
-      const url = `${API_BASE_URL}/api/stocks/${ticker}/aggregates?timespan=${timespan}&multiplier=${multiplier}&from=${from}&to=${to}`
+      // The API resolves the period to trading sessions and picks the bar size
+      const url = `${API_BASE_URL}/api/stocks/${ticker}/aggregates?period=${period}`
 
       const response = await authenticatedFetch(url)
 
@@ -201,4 +201,4 @@ export interface PreviousCloseResponse {
 /**
  * Time period options for the dashboard chart
  */
-export type TimePeriod = '1D' | '1W' | '1M' | '6M' | '1Y'
\ No newline at end of file
+export type TimePeriod = '1D' | '1W' | '1M' | '6M' | '1Y' | 'YTD' | '5Y' | 'MAX'
\ No newline at end of file